@@ -0,0 +1,91 @@
+package x25519
+
+import (
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"io"
+
+	"github.com/cloudflare/circl/nike"
+)
+
+type scheme struct{}
+
+// Scheme is X25519 as a non-interactive key exchange. Public keys that are
+// low-order points, which would yield an all-zero shared secret, are
+// rejected by DeriveSecret.
+var Scheme nike.Scheme = &scheme{}
+
+type publicKey struct{ k Key }
+type privateKey struct{ k Key }
+
+func (*scheme) Name() string        { return "X25519" }
+func (*scheme) PublicKeySize() int  { return Size }
+func (*scheme) PrivateKeySize() int { return Size }
+func (*scheme) SharedKeySize() int  { return Size }
+
+func (pk *publicKey) Scheme() nike.Scheme  { return Scheme }
+func (sk *privateKey) Scheme() nike.Scheme { return Scheme }
+
+func (pk *publicKey) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, pk.k[:]...), nil
+}
+
+func (sk *privateKey) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, sk.k[:]...), nil
+}
+
+func (pk *publicKey) Equal(other nike.PublicKey) bool {
+	oth, ok := other.(*publicKey)
+	return ok && pk.k == oth.k
+}
+
+func (sk *privateKey) Equal(other nike.PrivateKey) bool {
+	oth, ok := other.(*privateKey)
+	return ok && subtle.ConstantTimeCompare(sk.k[:], oth.k[:]) == 1
+}
+
+func (*scheme) GenerateKey() (nike.PublicKey, nike.PrivateKey, error) {
+	sk := new(privateKey)
+	if _, err := io.ReadFull(cryptoRand.Reader, sk.k[:]); err != nil {
+		return nil, nil, err
+	}
+	pk := new(publicKey)
+	KeyGen(&pk.k, &sk.k)
+	return pk, sk, nil
+}
+
+func (*scheme) DeriveSecret(sk nike.PrivateKey, pk nike.PublicKey) ([]byte, error) {
+	priv, ok := sk.(*privateKey)
+	if !ok {
+		return nil, nike.ErrTypeMismatch
+	}
+	pub, ok := pk.(*publicKey)
+	if !ok {
+		return nil, nike.ErrTypeMismatch
+	}
+
+	// Shared fails exactly when the shared secret is all zeros.
+	var ss Key
+	if !Shared(&ss, &priv.k, &pub.k) {
+		return nil, nike.ErrPubKey
+	}
+	return ss[:], nil
+}
+
+func (*scheme) UnmarshalBinaryPublicKey(buf []byte) (nike.PublicKey, error) {
+	if len(buf) != Size {
+		return nil, nike.ErrPubKeySize
+	}
+	pk := new(publicKey)
+	copy(pk.k[:], buf)
+	return pk, nil
+}
+
+func (*scheme) UnmarshalBinaryPrivateKey(buf []byte) (nike.PrivateKey, error) {
+	if len(buf) != Size {
+		return nil, nike.ErrPrivKeySize
+	}
+	sk := new(privateKey)
+	copy(sk.k[:], buf)
+	return sk, nil
+}
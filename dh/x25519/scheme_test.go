@@ -0,0 +1,30 @@
+package x25519
+
+import (
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/nike"
+)
+
+func TestSchemeLowOrder(t *testing.T) {
+	_, sk, err := Scheme.GenerateKey()
+	test.CheckNoErr(t, err, "key generation must succeed")
+
+	for i := range lowOrderPoints {
+		var P Key
+		copy(P[:], lowOrderPoints[i][:])
+		// The most significant bit is ignored, so it must not help to pass
+		// the check.
+		for _, msb := range []byte{0x00, 0x80} {
+			P[Size-1] |= msb
+			pk, err := Scheme.UnmarshalBinaryPublicKey(P[:])
+			test.CheckNoErr(t, err, "unmarshaling must succeed")
+
+			ss, err := Scheme.DeriveSecret(sk, pk)
+			if err != nike.ErrPubKey || ss != nil {
+				test.ReportError(t, err, nike.ErrPubKey, P)
+			}
+		}
+	}
+}
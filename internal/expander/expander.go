@@ -0,0 +1,106 @@
+// Package expander implements the expand_message functions of RFC 9380,
+// which map a message and a domain separation tag to a uniformly random
+// byte string of a given length.
+//
+// References:
+//  - RFC 9380 https://www.rfc-editor.org/rfc/rfc9380#section-5.3
+package expander
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/cloudflare/circl/internal/sha3"
+)
+
+// oversizeDSTPrefix is prepended to domain separation tags longer than
+// maxDSTSize bytes before hashing them into a shorter tag.
+const oversizeDSTPrefix = "H2C-OVERSIZE-DST-"
+
+// maxDSTSize is the maximum size, in bytes, of a domain separation tag.
+const maxDSTSize = 255
+
+var (
+	errXMDLength = errors.New("expander: requested length is too large for expand_message_xmd")
+	errXOFLength = errors.New("expander: requested length is too large for expand_message_xof")
+)
+
+// XMD returns n bytes computed with expand_message_xmd using the
+// Merkle-Damgård hash function newHash, the message msg and the domain
+// separation tag dst. Tags longer than 255 bytes are first hashed as
+// described in Section 5.3.3 of RFC 9380.
+//
+// It panics if n is larger than 255 times the output size of the hash
+// function, or larger than 65535.
+func XMD(newHash func() hash.Hash, msg, dst []byte, n int) []byte {
+	h := newHash()
+	ell := (n + h.Size() - 1) / h.Size()
+	if ell > 255 || n > 0xffff || n < 0 {
+		panic(errXMDLength)
+	}
+
+	if len(dst) > maxDSTSize {
+		_, _ = h.Write([]byte(oversizeDSTPrefix))
+		_, _ = h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	_, _ = h.Write(make([]byte, h.BlockSize()))
+	_, _ = h.Write(msg)
+	_, _ = h.Write([]byte{byte(n >> 8), byte(n), 0})
+	_, _ = h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	_, _ = h.Write(b0)
+	_, _ = h.Write([]byte{1})
+	_, _ = h.Write(dstPrime)
+	bi := h.Sum(nil)
+	out := append([]byte{}, bi...)
+	for i := 2; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		_, _ = h.Write(bi)
+		_, _ = h.Write([]byte{byte(i)})
+		_, _ = h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:n]
+}
+
+// XOF returns n bytes computed with expand_message_xof using the
+// extendable-output function xof, which targets a security level of k
+// bits, the message msg and the domain separation tag dst. The state of
+// xof is reset before use. Tags longer than 255 bytes are first hashed as
+// described in Section 5.3.3 of RFC 9380.
+//
+// It panics if n is larger than 65535.
+func XOF(xof sha3.ShakeHash, k int, msg, dst []byte, n int) []byte {
+	if n > 0xffff || n < 0 {
+		panic(errXOFLength)
+	}
+
+	xof.Reset()
+	if len(dst) > maxDSTSize {
+		_, _ = xof.Write([]byte(oversizeDSTPrefix))
+		_, _ = xof.Write(dst)
+		dst = make([]byte, (2*k+7)/8)
+		_, _ = xof.Read(dst)
+		xof.Reset()
+	}
+
+	_, _ = xof.Write(msg)
+	_, _ = xof.Write([]byte{byte(n >> 8), byte(n)})
+	_, _ = xof.Write(dst)
+	_, _ = xof.Write([]byte{byte(len(dst))})
+	out := make([]byte, n)
+	_, _ = xof.Read(out)
+
+	return out
+}
@@ -0,0 +1,99 @@
+// Package kschedule implements a key schedule for combining the shared
+// secrets of several KEMs, as in hybrid schemes, with HKDF.
+//
+// All the derivations are done with the LabeledExtract and LabeledExpand
+// functions of HPKE, so that every derived key is bound to the suite and to
+// the purpose of the derivation by its label. Combine derives a key from the
+// shared secrets and the ciphertexts of the KEMs. Binding the ciphertexts
+// prevents re-encapsulation attacks, in which the ciphertext of one of the
+// KEMs is replaced by another one that encapsulates the same shared secret.
+//
+// References:
+//  - RFC 9180 https://www.rfc-editor.org/rfc/rfc9180#section-4
+package kschedule
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// versionLabel is prepended to the labels of all derivations.
+const versionLabel = "HPKE-v1"
+
+var (
+	errExpandLength = errors.New("kschedule: requested length is too large")
+	errInputLength  = errors.New("kschedule: input is too long")
+	errMismatch     = errors.New("kschedule: number of shared secrets and ciphertexts differ")
+)
+
+// LabeledExtract returns HKDF-Extract(salt, "HPKE-v1" || suiteID || label ||
+// ikm) with the hash function newHash.
+func LabeledExtract(newHash func() hash.Hash, suiteID, salt, label, ikm []byte) []byte {
+	labeledIKM := make([]byte, 0, len(versionLabel)+len(suiteID)+len(label)+len(ikm))
+	labeledIKM = append(labeledIKM, versionLabel...)
+	labeledIKM = append(labeledIKM, suiteID...)
+	labeledIKM = append(labeledIKM, label...)
+	labeledIKM = append(labeledIKM, ikm...)
+	return hkdf.Extract(newHash, labeledIKM, salt)
+}
+
+// LabeledExpand returns n bytes of HKDF-Expand(prk, I2OSP(n, 2) || "HPKE-v1"
+// || suiteID || label || info) with the hash function newHash.
+//
+// It panics if n is larger than 255 times the output size of the hash
+// function, or larger than 65535.
+func LabeledExpand(newHash func() hash.Hash, suiteID, prk, label, info []byte, n int) []byte {
+	if n < 0 || n > 0xffff || n > 255*newHash().Size() {
+		panic(errExpandLength)
+	}
+	labeledInfo := make([]byte, 2, 2+len(versionLabel)+len(suiteID)+len(label)+len(info))
+	binary.BigEndian.PutUint16(labeledInfo, uint16(n))
+	labeledInfo = append(labeledInfo, versionLabel...)
+	labeledInfo = append(labeledInfo, suiteID...)
+	labeledInfo = append(labeledInfo, label...)
+	labeledInfo = append(labeledInfo, info...)
+
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.Expand(newHash, prk, labeledInfo), out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Combine returns an n-byte key derived from the shared secrets and the
+// ciphertexts of several KEMs, where ciphertexts[i] encapsulates
+// sharedSecrets[i]. The inputs are encoded with two-byte length prefixes,
+// so that distinct lists of inputs never collide, and the key is computed as
+//
+//  prk = LabeledExtract("", "hybrid_prk", ss_1 || ct_1 || ... || ss_k || ct_k)
+//  key = LabeledExpand(prk, "hybrid_key", info, n)
+//
+// It panics if the number of shared secrets and ciphertexts differ, if an
+// input is longer than 65535 bytes, or if n is too large for LabeledExpand.
+func Combine(newHash func() hash.Hash, suiteID []byte, sharedSecrets, ciphertexts [][]byte, info []byte, n int) []byte {
+	if len(sharedSecrets) != len(ciphertexts) {
+		panic(errMismatch)
+	}
+
+	var ikm []byte
+	for i := range sharedSecrets {
+		ikm = appendLengthPrefixed(ikm, sharedSecrets[i])
+		ikm = appendLengthPrefixed(ikm, ciphertexts[i])
+	}
+	prk := LabeledExtract(newHash, suiteID, nil, []byte("hybrid_prk"), ikm)
+	return LabeledExpand(newHash, suiteID, prk, []byte("hybrid_key"), info, n)
+}
+
+// appendLengthPrefixed appends I2OSP(len(x), 2) || x to b.
+func appendLengthPrefixed(b, x []byte) []byte {
+	if len(x) > 0xffff {
+		panic(errInputLength)
+	}
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(len(x)))
+	return append(append(b, l[:]...), x...)
+}
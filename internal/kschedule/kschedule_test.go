@@ -0,0 +1,88 @@
+package kschedule
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+)
+
+func hexDecode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestRFC9180 checks the key schedule of the test vector in Appendix A.1.1
+// of RFC 9180: DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-128-GCM, in
+// base mode.
+func TestRFC9180(t *testing.T) {
+	suiteID := []byte("HPKE\x00\x20\x00\x01\x00\x01")
+	sharedSecret := hexDecode("fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc")
+	info := hexDecode("4f6465206f6e2061204772656369616e2055726e")
+
+	pskIDHash := LabeledExtract(sha256.New, suiteID, nil, []byte("psk_id_hash"), nil)
+	infoHash := LabeledExtract(sha256.New, suiteID, nil, []byte("info_hash"), info)
+	context := append(append([]byte{0x00}, pskIDHash...), infoHash...)
+	secret := LabeledExtract(sha256.New, suiteID, sharedSecret, []byte("secret"), nil)
+
+	for _, v := range []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"key_schedule_context", context, "00725611c9d98c07c03f60095cd32d400d8347d45ed67097bbad50fc56da742d07cb6cffde367bb0565ba28bb02c90744a20f5ef37f30523526106f637abb05449"},
+		{"secret", secret, "12fff91991e93b48de37e7daddb52981084bd8aa64289c3788471d9a9712f397"},
+		{"key", LabeledExpand(sha256.New, suiteID, secret, []byte("key"), context, 16), "4531685d41d65f03dc48f6b8302c05b0"},
+		{"base_nonce", LabeledExpand(sha256.New, suiteID, secret, []byte("base_nonce"), context, 12), "56d890e5accaaf011cff4b7d"},
+		{"exporter_secret", LabeledExpand(sha256.New, suiteID, secret, []byte("exp"), context, 32), "45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8"},
+	} {
+		if want := hexDecode(v.want); !bytes.Equal(v.got, want) {
+			test.ReportError(t, v.got, want, v.name)
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	suiteID := []byte("test suite")
+	info := []byte("info")
+	ss := [][]byte{[]byte("shared secret 1"), []byte("shared secret 2")}
+	ct := [][]byte{[]byte("ciphertext 1"), []byte("ciphertext 2")}
+
+	key := Combine(sha256.New, suiteID, ss, ct, info, 32)
+	if len(key) != 32 {
+		test.ReportError(t, len(key), 32)
+	}
+	if got := Combine(sha256.New, suiteID, ss, ct, info, 32); !bytes.Equal(got, key) {
+		test.ReportError(t, got, key)
+	}
+
+	// Changing any input, including the ciphertexts and the boundaries
+	// between the inputs, changes the key.
+	for i, v := range []struct {
+		suiteID, info []byte
+		ss, ct        [][]byte
+	}{
+		{[]byte("other suite"), info, ss, ct},
+		{suiteID, []byte("other info"), ss, ct},
+		{suiteID, info, [][]byte{ss[1], ss[0]}, ct},
+		{suiteID, info, ss, [][]byte{ct[0], []byte("ciphertext 3")}},
+		{suiteID, info, ss, [][]byte{ct[1], ct[0]}},
+		{suiteID, info, [][]byte{[]byte("shared secret 1c"), ss[1]}, [][]byte{[]byte("iphertext 1"), ct[1]}},
+		{suiteID, info, ss[:1], ct[:1]},
+	} {
+		got := Combine(sha256.New, v.suiteID, v.ss, v.ct, v.info, 32)
+		if bytes.Equal(got, key) {
+			test.ReportError(t, got, key, i)
+		}
+	}
+
+	err := test.CheckPanic(func() { Combine(sha256.New, suiteID, ss, ct[:1], info, 32) })
+	test.CheckNoErr(t, err, "mismatched inputs must panic")
+	err = test.CheckPanic(func() { Combine(sha256.New, suiteID, ss, ct, info, 255*32+1) })
+	test.CheckNoErr(t, err, "too long output must panic")
+}
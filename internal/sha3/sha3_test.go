@@ -16,6 +16,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"strings"
@@ -98,9 +99,95 @@ func TestKeccakKats(t *testing.T) {
 				continue
 			}
 		}
+
+		for _, kat := range katSet.Kats["cSHAKE256"] {
+			N, err := hex.DecodeString(kat.N)
+			if err != nil {
+				t.Errorf("error decoding KAT: %s", err)
+			}
+			S, err := hex.DecodeString(kat.S)
+			if err != nil {
+				t.Errorf("error decoding KAT: %s", err)
+			}
+			in, err := hex.DecodeString(kat.Message)
+			if err != nil {
+				t.Errorf("error decoding KAT: %s", err)
+			}
+			d := NewCShake256(N, S)
+			_, _ = d.Write(in[:kat.Length/8])
+			out := make([]byte, len(kat.Digest)/2)
+			_, _ = d.Read(out)
+			got := strings.ToUpper(hex.EncodeToString(out))
+			if got != kat.Digest {
+				t.Errorf("function=cSHAKE256, implementation=%s, length=%d\nmessage:\n %s\ngot:\n  %s\nwanted:\n %s",
+					impl, kat.Length, kat.Message, got, kat.Digest)
+				t.FailNow()
+			}
+		}
 	})
 }
 
+func TestXOFReader(t *testing.T) {
+	seed := []byte("seed")
+	fn := []byte("key derivation")
+	out := func(r io.Reader) []byte {
+		buf := make([]byte, 1000)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf
+	}
+
+	a := out(NewXOFReader(seed, fn, []byte("subkey 1")))
+	b := out(NewXOFReader(seed, fn, []byte("subkey 1")))
+	if !bytes.Equal(a, b) {
+		t.Fatal("streams with the same inputs must be equal")
+	}
+
+	c := out(NewXOFReader(seed, fn, []byte("subkey 2")))
+	d := out(NewXOFReader(seed, []byte("other function"), []byte("subkey 1")))
+	for _, x := range [][]byte{c, d} {
+		for i := 0; i+8 <= len(a); i += 8 {
+			if bytes.Equal(a[i:i+8], x[i:i+8]) {
+				t.Fatal("streams with different domains must be independent")
+			}
+		}
+	}
+}
+
+// TestCShakeBytepadBoundary tests cSHAKE256 when the encoded N and S fill
+// exactly one block, so that bytepad must not append any padding.
+func TestCShakeBytepadBoundary(t *testing.T) {
+	S := bytes.Repeat([]byte{0xA5}, 129)
+	msg := []byte("message")
+
+	// left_encode(136) || left_encode(0) || left_encode(8*129) || S is
+	// 2+2+3+129 = 136 bytes long, which is the rate of cSHAKE256.
+	block := append([]byte{1, 136, 1, 0, 2, 0x04, 0x08}, S...)
+	if len(block) != rate256 {
+		t.Fatalf("block has %v bytes, want %v", len(block), rate256)
+	}
+	want := State{rate: rate256, dsbyte: dsbyteCShake}
+	_, _ = want.Write(block)
+	_, _ = want.Write(msg)
+	wantOut := make([]byte, 64)
+	_, _ = want.Read(wantOut)
+
+	got := NewCShake256(nil, S)
+	_, _ = got.Write(msg)
+	gotOut := make([]byte, 64)
+	_, _ = got.Read(gotOut)
+	if !bytes.Equal(gotOut, wantOut) {
+		t.Errorf("got %x, want %x", gotOut, wantOut)
+	}
+
+	for _, n := range []int{0, 1, 134, 135, 136, 270} {
+		if l := len(bytepad(make([]byte, n), rate256)); l%rate256 != 0 || l-rate256 >= n+2 {
+			t.Errorf("bytepad of %v bytes has %v bytes", n, l)
+		}
+	}
+}
+
 // TestUnalignedWrite tests that writing data in an arbitrary pattern with
 // small input buffers.
 func TestUnalignedWrite(t *testing.T) {
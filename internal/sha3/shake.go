@@ -16,6 +16,7 @@ package sha3
 // [2] https://doi.org/10.6028/NIST.SP.800-185
 
 import (
+	"encoding/binary"
 	"io"
 )
 
@@ -40,9 +41,10 @@ type ShakeHash interface {
 
 // Consts for configuring initial SHA-3 state
 const (
-	dsbyteShake = 0x1f
-	rate128     = 168
-	rate256     = 136
+	dsbyteShake  = 0x1f
+	dsbyteCShake = 0x04
+	rate128      = 168
+	rate256      = 136
 )
 
 // Clone returns copy of SHAKE context within its current state.
@@ -77,3 +79,66 @@ func ShakeSum256(hash, data []byte) {
 	_, _ = h.Write(data)
 	_, _ = h.Read(hash)
 }
+
+// NewCShake256 creates a new instance of cSHAKE256 variable-output-length
+// ShakeHash, a customizable variant of SHAKE256.
+// N is used to define functions based on cSHAKE, it can be empty when plain
+// cSHAKE is desired. S is a customization byte string used for domain
+// separation. When N and S are both empty, this is equivalent to SHAKE256.
+// Note that Reset discards N and S.
+func NewCShake256(N, S []byte) State {
+	if len(N) == 0 && len(S) == 0 {
+		return NewShake256()
+	}
+
+	d := State{rate: rate256, dsbyte: dsbyteCShake}
+	// leftEncode returns at most 9 bytes.
+	initBlock := make([]byte, 0, 9*2+len(N)+len(S))
+	initBlock = append(initBlock, leftEncode(uint64(len(N)*8))...)
+	initBlock = append(initBlock, N...)
+	initBlock = append(initBlock, leftEncode(uint64(len(S)*8))...)
+	initBlock = append(initBlock, S...)
+
+	// The padded block is a multiple of the rate, so it is absorbed without
+	// using d.buf, which must not point into d.storage as d is returned by
+	// value.
+	for block := bytepad(initBlock, d.rate); len(block) > 0; block = block[d.rate:] {
+		xorIn(&d, block[:d.rate])
+		KeccakF1600(&d.a)
+	}
+	return d
+}
+
+// NewXOFReader returns an unbounded stream of bytes derived from seed using
+// cSHAKE256, with fn as the function name and customization as the
+// customization string. The stream is deterministic for given inputs, and
+// streams with different function names or customizations are independent.
+func NewXOFReader(seed, fn, customization []byte) io.Reader {
+	h := NewCShake256(fn, customization)
+	_, _ = h.Write(seed)
+	return &h
+}
+
+func bytepad(input []byte, w int) []byte {
+	// leftEncode always returns max 9 bytes.
+	buf := make([]byte, 0, 9+len(input)+w)
+	buf = append(buf, leftEncode(uint64(w))...)
+	buf = append(buf, input...)
+	if r := len(buf) % w; r != 0 {
+		buf = append(buf, make([]byte, w-r)...)
+	}
+	return buf
+}
+
+func leftEncode(value uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[1:], value)
+	// Trim all but last leading zero bytes.
+	i := byte(1)
+	for i < 8 && b[i] == 0 {
+		i++
+	}
+	// Prepend number of encoded bytes.
+	b[i-1] = 9 - i
+	return b[i-1:]
+}
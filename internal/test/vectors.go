@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ReadJSON decodes the JSON vectors in the file at path into v.
+func ReadJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+	return nil
+}
+
+// ReadKAT parses the vectors of a NIST KAT file, such as the PQCsignKAT_*
+// and PQCkemKAT_* request and response files, read from r. The argument vs
+// must be a pointer to a slice of structs, to which a struct is appended
+// for each vector.
+//
+// Each line of the file is either empty, a # comment, or a "key = value"
+// assignment, and each vector starts with a count assignment. A value is
+// stored in the exported field tagged `kat:"key"`, which is parsed as a
+// decimal number for int fields, hex-decoded for []byte fields, and kept
+// as is for string fields. Empty values, as the outputs in request files,
+// are skipped. An int field tagged `kat:",line"` is set to the line number
+// of the count of the vector, to report mismatches in context.
+//
+// Errors refer to name, and to the line that could not be parsed.
+func ReadKAT(r io.Reader, name string, vs interface{}) error {
+	slice := reflect.ValueOf(vs)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice ||
+		slice.Elem().Type().Elem().Kind() != reflect.Struct {
+		return errors.New("ReadKAT: vs must be a pointer to a slice of structs")
+	}
+	slice = slice.Elem()
+	typ := slice.Type().Elem()
+	fields := make(map[string]int)
+	lineField := -1
+	for i := 0; i < typ.NumField(); i++ {
+		switch tag := typ.Field(i).Tag.Get("kat"); tag {
+		case "":
+		case ",line":
+			lineField = i
+		default:
+			fields[tag] = i
+		}
+	}
+
+	var v reflect.Value
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%v:%v: malformed line %q", name, n, line)
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if key == "count" {
+			slice.Set(reflect.Append(slice, reflect.Zero(typ)))
+			v = slice.Index(slice.Len() - 1)
+			if lineField >= 0 {
+				v.Field(lineField).SetInt(int64(n))
+			}
+		} else if !v.IsValid() {
+			return fmt.Errorf("%v:%v: %v before count", name, n, key)
+		}
+		i, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%v:%v: unknown field %v", name, n, key)
+		}
+		if val == "" {
+			continue
+		}
+
+		var err error
+		f := v.Field(i)
+		switch f.Interface().(type) {
+		case int:
+			var x int
+			x, err = strconv.Atoi(val)
+			f.SetInt(int64(x))
+		case []byte:
+			var b []byte
+			b, err = hex.DecodeString(val)
+			f.SetBytes(b)
+		case string:
+			f.SetString(val)
+		default:
+			err = fmt.Errorf("unsupported type %v", f.Type())
+		}
+		if err != nil {
+			return fmt.Errorf("%v:%v: %v: %v", name, n, key, err)
+		}
+	}
+	return scanner.Err()
+}
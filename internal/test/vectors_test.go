@@ -0,0 +1,72 @@
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type katVector struct {
+	Line  int    `kat:",line"`
+	Count int    `kat:"count"`
+	Msg   []byte `kat:"msg"`
+	Name  string `kat:"name"`
+}
+
+func TestReadKAT(t *testing.T) {
+	const kat = "# comment\n\ncount = 0\nmsg = 0102\nname = a b\n\n" +
+		"count = 1\nmsg =\nname = c\n"
+	var vs []katVector
+	err := ReadKAT(strings.NewReader(kat), "kat", &vs)
+	CheckNoErr(t, err, "ReadKAT failed")
+	if len(vs) != 2 ||
+		vs[0].Line != 3 || vs[0].Count != 0 || !bytes.Equal(vs[0].Msg, []byte{1, 2}) || vs[0].Name != "a b" ||
+		vs[1].Line != 7 || vs[1].Count != 1 || vs[1].Msg != nil || vs[1].Name != "c" {
+		t.Fatalf("unexpected vectors: %+v", vs)
+	}
+
+	for _, v := range []struct{ kat, err string }{
+		{"msg = 00\n", "kat:1: msg before count"},
+		{"count = 0\nmsg\n", `kat:2: malformed line "msg"`},
+		{"count = 0\nseed = 00\n", "kat:2: unknown field seed"},
+		{"count = 0\n\nmsg = 0x\n", "kat:3: msg: "},
+		{"count = x\n", "kat:1: count: "},
+	} {
+		var vs []katVector
+		err := ReadKAT(strings.NewReader(v.kat), "kat", &vs)
+		if err == nil || !strings.HasPrefix(err.Error(), v.err) {
+			ReportError(t, err, v.err, v.kat)
+		}
+	}
+
+	var notSlice katVector
+	CheckIsErr(t, ReadKAT(strings.NewReader(""), "kat", &notSlice), "vs must be a slice")
+}
+
+func TestReadJSON(t *testing.T) {
+	f, err := ioutil.TempFile("", "vectors*.json")
+	CheckNoErr(t, err, "TempFile failed")
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`[{"count": 0, "msg": "0102"}, {"count": 1}]`)
+	CheckNoErr(t, err, "WriteString failed")
+	CheckNoErr(t, f.Close(), "Close failed")
+
+	var vs []struct {
+		Count int    `json:"count"`
+		Msg   string `json:"msg"`
+	}
+	CheckNoErr(t, ReadJSON(f.Name(), &vs), "ReadJSON failed")
+	if len(vs) != 2 || vs[0].Count != 0 || vs[0].Msg != "0102" ||
+		vs[1].Count != 1 || vs[1].Msg != "" {
+		t.Fatalf("unexpected vectors: %+v", vs)
+	}
+
+	var m map[string]int
+	err = ReadJSON(f.Name(), &m)
+	if err == nil || !strings.HasPrefix(err.Error(), f.Name()+": ") {
+		ReportError(t, err, f.Name()+": ...")
+	}
+	CheckIsErr(t, ReadJSON(f.Name()+".missing", &vs), "missing file")
+}
@@ -32,9 +32,15 @@ func main() {
 	generatePackageFiles()
 }
 
-// Generates instance/kyber.go from templates/pkg.templ.go
+// Generates instance/kyber.go from templates/pkg.templ.go, and
+// instance/kyber_test.go from templates/pkg_test.templ.go.
 func generatePackageFiles() {
-	tl, err := template.ParseFiles("templates/pkg.templ.go")
+	generateFile("templates/pkg.templ.go", "kyber.go")
+	generateFile("templates/pkg_test.templ.go", "kyber_test.go")
+}
+
+func generateFile(templ, file string) {
+	tl, err := template.ParseFiles(templ)
 	if err != nil {
 		panic(err)
 	}
@@ -49,9 +55,9 @@ func generatePackageFiles() {
 		res := string(buf.Bytes())
 		offset := strings.Index(res, TemplateWarning)
 		if offset == -1 {
-			panic("Missing template warning in pkg.templ.go")
+			panic("Missing template warning in " + templ)
 		}
-		err = ioutil.WriteFile(mode.Pkg()+"/kyber.go", []byte(res[offset:]), 0644)
+		err = ioutil.WriteFile(mode.Pkg()+"/"+file, []byte(res[offset:]), 0644)
 		if err != nil {
 			panic(err)
 		}
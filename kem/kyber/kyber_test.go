@@ -0,0 +1,57 @@
+package kyber
+
+import (
+	"testing"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/schemes"
+)
+
+func TestPublicKeyModulusCheck(t *testing.T) {
+	for _, name := range []string{"Kyber512", "Kyber768", "Kyber1024"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			scheme := schemes.ByName(name)
+			if scheme == nil {
+				t.Fatal()
+			}
+
+			pk, _, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ppk, err := pk.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = scheme.UnmarshalBinaryPublicKey(ppk); err != nil {
+				t.Fatal(err)
+			}
+
+			// Set the first coefficient of t̂ to 4095 ≥ q.
+			ppk[0] = 0xff
+			ppk[1] |= 0x0f
+			_, err = scheme.UnmarshalBinaryPublicKey(ppk)
+			if err != kem.ErrPubKey {
+				t.Fatalf("expected %v, got %v", kem.ErrPubKey, err)
+			}
+
+			// An all-zero t̂ is canonical and thus accepted.
+			for i := 0; i < len(ppk)-32; i++ {
+				ppk[i] = 0
+			}
+			if _, err = scheme.UnmarshalBinaryPublicKey(ppk); err != nil {
+				t.Fatal(err)
+			}
+
+			// An all-one t̂ is not.
+			for i := 0; i < len(ppk)-32; i++ {
+				ppk[i] = 0xff
+			}
+			_, err = scheme.UnmarshalBinaryPublicKey(ppk)
+			if err != kem.ErrPubKey {
+				t.Fatalf("expected %v, got %v", kem.ErrPubKey, err)
+			}
+		})
+	}
+}
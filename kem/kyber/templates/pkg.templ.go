@@ -167,6 +167,16 @@ func (sk *PrivateKey) DecapsulateTo(ss, ct []byte) {
 		panic("ss must be of length SharedKeySize")
 	}
 
+	sk.decapsulateWithFlag(ss, ct)
+}
+
+// decapsulateWithFlag computes the shared key as DecapsulateTo does, and
+// returns 1 if ct is the re-encryption of its plaintext, that is, if ss is
+// the real shared key, and 0 if ss is the pseudo-random key derived from z
+// (implicit rejection). The selection between both keys is done with a
+// mask, without branching on the comparison; the flag is only returned for
+// testing, and must not be branched on either.
+func (sk *PrivateKey) decapsulateWithFlag(ss, ct []byte) int {
 	// m' = Kyber.CPAPKE.Dec(sk, ct)
 	var m2 [32]byte
 	sk.sk.DecryptTo(m2[:], ct)
@@ -188,16 +198,15 @@ func (sk *PrivateKey) DecapsulateTo(ss, ct []byte) {
 	h.Sum(kr2[32:32])
 
 	// Replace K'' by  z in the first slot of kr2 if c ≠ c'.
-	subtle.ConstantTimeCopy(
-		1-subtle.ConstantTimeCompare(ct, ct2[:]),
-		kr2[:32],
-		sk.z[:],
-	)
+	ok := subtle.ConstantTimeCompare(ct, ct2[:])
+	subtle.ConstantTimeCopy(1-ok, kr2[:32], sk.z[:])
 
 	// K = KDF(K''/z, H(c))
 	kdf := sha3.NewShake256()
 	kdf.Write(kr2[:])
 	kdf.Read(ss[:SharedKeySize])
+
+	return ok
 }
 
 // Packs sk to buf.
@@ -229,7 +238,9 @@ func (sk *PrivateKey) Unpack(buf []byte) {
 	sk.sk.Unpack(buf[:cpapke.PrivateKeySize])
 	buf = buf[cpapke.PrivateKeySize:]
 	sk.pk = new(cpapke.PublicKey)
-	sk.pk.Unpack(buf[:cpapke.PublicKeySize])
+	// The private key is trusted, so its public key is not checked, and
+	// non-canonical coefficients are just reduced.
+	_ = sk.pk.Unpack(buf[:cpapke.PublicKeySize])
 	buf = buf[cpapke.PublicKeySize:]
 	copy(sk.hpk[:], buf[:32])
 	copy(sk.z[:], buf[32:])
@@ -248,19 +259,24 @@ func (pk *PublicKey) Pack(buf []byte) {
 
 // Unpacks pk from buf.
 //
-// Panics if buf is not of size PublicKeySize.
-func (pk *PublicKey) Unpack(buf []byte) {
+// Returns kem.ErrPubKey if the coefficients of pk are not canonical, that
+// is, smaller than q.  Panics if buf is not of size PublicKeySize.
+func (pk *PublicKey) Unpack(buf []byte) error {
 	if len(buf) != PublicKeySize {
 		panic("buf must be of length PublicKeySize")
 	}
 
 	pk.pk = new(cpapke.PublicKey)
-	pk.pk.Unpack(buf)
+	if err := pk.pk.Unpack(buf); err != nil {
+		return kem.ErrPubKey
+	}
 
 	// Compute cached H(pk)
 	h := sha3.New256()
 	h.Write(buf)
 	h.Sum(pk.hpk[:0])
+
+	return nil
 }
 
 // Boilerplate down below for the KEM scheme API.
@@ -383,7 +399,9 @@ func (*scheme) UnmarshalBinaryPublicKey(buf []byte) (kem.PublicKey, error) {
 		return nil, kem.ErrPubKeySize
 	}
 	var ret PublicKey
-	ret.Unpack(buf)
+	if err := ret.Unpack(buf); err != nil {
+		return nil, err
+	}
 	return &ret, nil
 }
 
@@ -0,0 +1,145 @@
+// Package signcrypt signs and encrypts messages in one step, with an
+// Ed25519 key of the sender and the public key of a KEM scheme, such as
+// Kyber, of the recipient.
+//
+// Signcrypt signs the plaintext along with the KEM ciphertext, which binds
+// the signature to the recipient, so that it cannot be forwarded encrypted
+// to somebody else. It then encrypts the signature and the plaintext with
+// AES-256-GCM under a key derived from the shared secret, authenticating
+// the public key of the sender and the KEM ciphertext as additional data.
+//
+// The wire format is
+//
+//   version (1 byte) ‖ sender public key (32 bytes) ‖ KEM ciphertext ‖
+//   AES-256-GCM(signature (64 bytes) ‖ plaintext),
+//
+// where the version is 1, and the nonce of AES-256-GCM is derived along
+// with the key, as it is used once.
+package signcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/cloudflare/circl/internal/kschedule"
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/sign/ed25519"
+)
+
+const (
+	// Version is the version of the wire format written by Signcrypt.
+	Version = 1
+
+	// context of the Ed25519 signatures, as in Ed25519ctx.
+	context = "circl signcrypt v1"
+
+	keySize   = 32
+	nonceSize = 12
+	tagSize   = 16
+)
+
+var (
+	// ErrMalformed is returned by Unsigncrypt for inputs that do not follow
+	// the wire format, including those of another version.
+	ErrMalformed = errors.New("signcrypt: malformed message")
+
+	// ErrDecryption is returned by Unsigncrypt for messages that fail to
+	// decrypt, or whose signature is not valid.
+	ErrDecryption = errors.New("signcrypt: decryption failed")
+)
+
+// Overhead returns the number of bytes that Signcrypt adds to plaintexts
+// encrypted to public keys of scheme.
+func Overhead(scheme kem.Scheme) int {
+	return 1 + ed25519.PublicKeySize + scheme.CiphertextSize() +
+		ed25519.SignatureSize + tagSize
+}
+
+// Signcrypt signs plaintext with senderSK and encrypts it to recipientPK,
+// and returns the resulting message.
+func Signcrypt(senderSK ed25519.PrivateKey, recipientPK kem.PublicKey, plaintext []byte) ([]byte, error) {
+	if len(senderSK) != ed25519.PrivateKeySize {
+		return nil, errors.New("signcrypt: bad private key length")
+	}
+	scheme := recipientPK.Scheme()
+	ct, ss := scheme.Encapsulate(recipientPK)
+	aead, nonce, err := deriveAEAD(scheme, ss, ct)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 1+ed25519.PublicKeySize+len(ct))
+	header = append(header, Version)
+	header = append(header, senderSK[ed25519.SeedSize:]...)
+	header = append(header, ct...)
+
+	sig := ed25519.SignWithCtx(senderSK, signedMessage(ct, plaintext), context)
+	payload := append(sig, plaintext...)
+
+	out := make([]byte, len(header), Overhead(scheme)+len(plaintext))
+	copy(out, header)
+	return aead.Seal(out, nonce, payload, header), nil
+}
+
+// Unsigncrypt decrypts the message produced by Signcrypt with recipientSK,
+// verifies its signature, and returns the plaintext and the public key of
+// the sender.
+//
+// The message only proves that it was signcrypted with the returned key,
+// so the caller must check that it is the key of a trusted sender.
+func Unsigncrypt(recipientSK kem.PrivateKey, message []byte) ([]byte, ed25519.PublicKey, error) {
+	scheme := recipientSK.Scheme()
+	headerSize := 1 + ed25519.PublicKeySize + scheme.CiphertextSize()
+	if len(message) < Overhead(scheme) || message[0] != Version {
+		return nil, nil, ErrMalformed
+	}
+	header := message[:headerSize]
+	sender := ed25519.PublicKey(append([]byte{}, header[1:1+ed25519.PublicKeySize]...))
+	ct := header[1+ed25519.PublicKeySize:]
+
+	ss := scheme.Decapsulate(recipientSK, ct)
+	aead, nonce, err := deriveAEAD(scheme, ss, ct)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := aead.Open(nil, nonce, message[headerSize:], header)
+	if err != nil {
+		return nil, nil, ErrDecryption
+	}
+
+	sig, plaintext := payload[:ed25519.SignatureSize], payload[ed25519.SignatureSize:]
+	if !ed25519.VerifyWithCtx(sender, signedMessage(ct, plaintext), sig, context) {
+		return nil, nil, ErrDecryption
+	}
+	return plaintext, sender, nil
+}
+
+// signedMessage returns the message signed by the sender, which is the KEM
+// ciphertext ct followed by the plaintext. As ct has a fixed size for a
+// given scheme, the encoding is unambiguous.
+func signedMessage(ct, plaintext []byte) []byte {
+	m := make([]byte, 0, len(ct)+len(plaintext))
+	m = append(m, ct...)
+	return append(m, plaintext...)
+}
+
+// deriveAEAD returns the AES-256-GCM AEAD and its nonce derived from the
+// shared secret ss and the ciphertext ct that encapsulates it.
+func deriveAEAD(scheme kem.Scheme, ss, ct []byte) (cipher.AEAD, []byte, error) {
+	suiteID := []byte("signcrypt " + scheme.Name())
+	prk := kschedule.LabeledExtract(sha256.New, suiteID, nil, []byte("signcrypt_prk"), append(append([]byte{}, ct...), ss...))
+	key := kschedule.LabeledExpand(sha256.New, suiteID, prk, []byte("key"), nil, keySize)
+	nonce := kschedule.LabeledExpand(sha256.New, suiteID, prk, []byte("nonce"), nil, nonceSize)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, nonce, nil
+}
@@ -0,0 +1,108 @@
+package signcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/kem/schemes"
+	"github.com/cloudflare/circl/sign/ed25519"
+)
+
+func TestSigncrypt(t *testing.T) {
+	senderPK, senderSK, err := ed25519.GenerateKey(rand.Reader)
+	test.CheckNoErr(t, err, "key generation must succeed")
+	for _, scheme := range schemes.All() {
+		pk, sk, err := scheme.GenerateKey()
+		test.CheckNoErr(t, err, "key generation must succeed")
+
+		for _, pt := range [][]byte{nil, []byte("hello"), make([]byte, 1000)} {
+			msg, err := Signcrypt(senderSK, pk, pt)
+			test.CheckNoErr(t, err, "signcryption must succeed")
+			if len(msg) != Overhead(scheme)+len(pt) {
+				test.ReportError(t, len(msg), Overhead(scheme)+len(pt), scheme.Name())
+			}
+
+			got, sender, err := Unsigncrypt(sk, msg)
+			test.CheckNoErr(t, err, "unsigncryption must succeed")
+			if !bytes.Equal(got, pt) {
+				test.ReportError(t, got, pt, scheme.Name())
+			}
+			if !bytes.Equal(sender, senderPK) {
+				test.ReportError(t, sender, senderPK, scheme.Name())
+			}
+		}
+	}
+}
+
+func TestUnsigncryptTampered(t *testing.T) {
+	_, senderSK, _ := ed25519.GenerateKey(rand.Reader)
+	otherPK, _, _ := ed25519.GenerateKey(rand.Reader)
+	scheme := schemes.ByName("Kyber768")
+	pk, sk, _ := scheme.GenerateKey()
+	_, sk2, _ := scheme.GenerateKey()
+
+	msg, err := Signcrypt(senderSK, pk, []byte("hello"))
+	test.CheckNoErr(t, err, "signcryption must succeed")
+
+	// Malformed messages.
+	for _, bad := range [][]byte{
+		nil,
+		msg[:Overhead(scheme)-1],
+		append([]byte{Version + 1}, msg[1:]...),
+	} {
+		if _, _, err = Unsigncrypt(sk, bad); err != ErrMalformed {
+			test.ReportError(t, err, ErrMalformed)
+		}
+	}
+
+	// Every byte of the sender key, KEM ciphertext and payload is
+	// authenticated.
+	for i := 1; i < len(msg); i++ {
+		bad := append([]byte{}, msg...)
+		bad[i] ^= 1
+		if _, _, err = Unsigncrypt(sk, bad); err != ErrDecryption {
+			test.ReportError(t, err, ErrDecryption, i)
+		}
+	}
+
+	// Swapping the sender key.
+	bad := append([]byte{}, msg...)
+	copy(bad[1:], otherPK)
+	if _, _, err = Unsigncrypt(sk, bad); err != ErrDecryption {
+		test.ReportError(t, err, ErrDecryption)
+	}
+
+	// Wrong recipient.
+	if _, _, err = Unsigncrypt(sk2, msg); err != ErrDecryption {
+		test.ReportError(t, err, ErrDecryption)
+	}
+}
+
+func TestUnsigncryptForwarded(t *testing.T) {
+	_, senderSK, _ := ed25519.GenerateKey(rand.Reader)
+	scheme := schemes.ByName("Kyber512")
+	pk, sk, _ := scheme.GenerateKey()
+	pk2, sk2, _ := scheme.GenerateKey()
+
+	msg, err := Signcrypt(senderSK, pk, []byte("hello"))
+	test.CheckNoErr(t, err, "signcryption must succeed")
+
+	// The recipient decrypts the signature and the plaintext, and encrypts
+	// them to somebody else on behalf of the sender.
+	headerSize := 1 + ed25519.PublicKeySize + scheme.CiphertextSize()
+	ct := msg[1+ed25519.PublicKeySize : headerSize]
+	aead, nonce, _ := deriveAEAD(scheme, scheme.Decapsulate(sk, ct), ct)
+	payload, err := aead.Open(nil, nonce, msg[headerSize:], msg[:headerSize])
+	test.CheckNoErr(t, err, "decryption must succeed")
+
+	ct2, ss2 := scheme.Encapsulate(pk2)
+	header := append(append([]byte{Version}, msg[1:1+ed25519.PublicKeySize]...), ct2...)
+	aead, nonce, _ = deriveAEAD(scheme, ss2, ct2)
+	forwarded := aead.Seal(header, nonce, payload, header)
+
+	if _, _, err = Unsigncrypt(sk2, forwarded); err != ErrDecryption {
+		test.ReportError(t, err, ErrDecryption)
+	}
+}
@@ -0,0 +1,171 @@
+// Package stream encrypts payloads of any size to the public key of a KEM
+// scheme, in chunks that are decrypted one at a time without buffering the
+// whole payload.
+//
+// The sender calls StreamSeal, which writes a short header made of the id
+// of the AEAD followed by the KEM ciphertext, and the receiver calls
+// StreamOpen with the header. Both get the AEAD, AES-256-GCM by default or
+// the one chosen with StreamSealWithAEAD, and a nonce base derived from the
+// shared secret and the header. Each chunk is then encrypted with the nonce given by ChunkNonce,
+// which encodes the position of the chunk and whether it is the last one,
+// as in the STREAM construction of Hoang, Reyhanitabar, Rogaway and Vizár.
+// Hence, reordered, dropped or appended chunks fail to decrypt, and so does
+// a truncated stream, as its last chunk was not encrypted as such.
+//
+// References:
+//  - Online Authenticated-Encryption and its Nonce-Reuse Misuse-Resistance
+//    https://eprint.iacr.org/2015/189
+package stream
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/cloudflare/circl/internal/kschedule"
+	"github.com/cloudflare/circl/kem"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// KeySize is the size, in bytes, of the key of the AEAD.
+	KeySize = 32
+
+	// NonceSize is the size, in bytes, of the nonce base and of the nonces
+	// returned by ChunkNonce.
+	NonceSize = 12
+)
+
+// AEADID identifies the AEAD that encrypts the chunks of a stream. It is
+// written in the header, so StreamOpen picks the AEAD of the sender.
+type AEADID uint8
+
+const (
+	// AES256GCM is AES-256 in GCM mode, the default, which is fast on
+	// platforms with AES instructions.
+	AES256GCM AEADID = 1 + iota
+	// ChaCha20Poly1305 is ChaCha20-Poly1305 as in RFC 8439, which is fast
+	// on platforms without AES instructions.
+	ChaCha20Poly1305
+)
+
+// ErrUnknownAEAD is returned for AEADIDs that are not supported, including
+// when reading them from a header.
+var ErrUnknownAEAD = errors.New("stream: unknown AEAD")
+
+// label returns the label used to derive the key of the AEAD, which is
+// specific to each AEAD.
+func (id AEADID) label() []byte {
+	switch id {
+	case AES256GCM:
+		return []byte("aes256gcm_key")
+	case ChaCha20Poly1305:
+		return []byte("chacha20poly1305_key")
+	default:
+		return nil
+	}
+}
+
+// new returns the AEAD with the given key of size KeySize.
+func (id AEADID) new(key []byte) (cipher.AEAD, error) {
+	switch id {
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, ErrUnknownAEAD
+	}
+}
+
+// HeaderSize returns the size, in bytes, of the headers of streams sealed
+// to the public keys of scheme.
+func HeaderSize(scheme kem.Scheme) int { return 1 + scheme.CiphertextSize() }
+
+// StreamSeal encapsulates a shared secret to pk, writes the header to
+// header, and returns the AES-256-GCM AEAD and the nonce base that encrypt
+// the chunks of the payload with the nonces of ChunkNonce.
+func StreamSeal(pk kem.PublicKey, header io.Writer) (cipher.AEAD, []byte, error) {
+	return StreamSealWithAEAD(pk, AES256GCM, header)
+}
+
+// StreamSealWithAEAD is like StreamSeal, but returns the AEAD identified
+// by id. It returns ErrUnknownAEAD if id is not supported.
+func StreamSealWithAEAD(pk kem.PublicKey, id AEADID, header io.Writer) (cipher.AEAD, []byte, error) {
+	if id.label() == nil {
+		return nil, nil, ErrUnknownAEAD
+	}
+	scheme := pk.Scheme()
+	ct, ss := scheme.Encapsulate(pk)
+	if _, err := header.Write(append([]byte{byte(id)}, ct...)); err != nil {
+		return nil, nil, err
+	}
+	return deriveAEAD(scheme, id, ss, ct)
+}
+
+// StreamOpen reads the header written by StreamSeal, decapsulates the
+// shared secret with sk, and returns the AEAD and the nonce base that
+// decrypt the chunks of the payload with the nonces of ChunkNonce.
+//
+// It only reads the header from the reader, so the chunks may follow it.
+// It returns ErrUnknownAEAD if the header does not name a supported AEAD.
+func StreamOpen(sk kem.PrivateKey, header io.Reader) (cipher.AEAD, []byte, error) {
+	scheme := sk.Scheme()
+	buf := make([]byte, HeaderSize(scheme))
+	if _, err := io.ReadFull(header, buf[:1]); err != nil {
+		return nil, nil, err
+	}
+	id := AEADID(buf[0])
+	if id.label() == nil {
+		return nil, nil, ErrUnknownAEAD
+	}
+	ct := buf[1:]
+	if _, err := io.ReadFull(header, ct); err != nil {
+		return nil, nil, err
+	}
+	ss := scheme.Decapsulate(sk, ct)
+	return deriveAEAD(scheme, id, ss, ct)
+}
+
+// deriveAEAD returns the AEAD identified by id and the nonce base derived
+// from the shared secret ss and the ciphertext ct that encapsulates it.
+func deriveAEAD(scheme kem.Scheme, id AEADID, ss, ct []byte) (cipher.AEAD, []byte, error) {
+	suiteID := []byte("stream " + scheme.Name())
+	prk := kschedule.LabeledExtract(sha256.New, suiteID, nil, []byte("stream_prk"), append(append([]byte{}, ct...), ss...))
+	key := kschedule.LabeledExpand(sha256.New, suiteID, prk, id.label(), nil, KeySize)
+	nonceBase := kschedule.LabeledExpand(sha256.New, suiteID, prk, []byte("base_nonce"), nil, NonceSize)
+
+	aead, err := id.new(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, nonceBase, nil
+}
+
+// ChunkNonce returns the nonce of the chunk with index counter of a
+// stream, which is the nonce base XORed with the big-endian counter and a
+// final byte that is one for the last chunk and zero otherwise.
+//
+// It panics if len(nonceBase) is not NonceSize.
+func ChunkNonce(nonceBase []byte, counter uint64, last bool) []byte {
+	if len(nonceBase) != NonceSize {
+		panic("stream: bad nonce base length")
+	}
+	var enc [NonceSize]byte
+	binary.BigEndian.PutUint64(enc[NonceSize-9:], counter)
+	if last {
+		enc[NonceSize-1] = 1
+	}
+	nonce := make([]byte, NonceSize)
+	for i := range nonce {
+		nonce[i] = nonceBase[i] ^ enc[i]
+	}
+	return nonce
+}
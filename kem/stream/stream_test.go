@@ -0,0 +1,159 @@
+package stream_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"fmt"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/schemes"
+	"github.com/cloudflare/circl/kem/stream"
+)
+
+// open decrypts the chunks, and returns an error if any of them fails to
+// decrypt, or if the last one is not marked as such.
+func open(aead cipher.AEAD, nonceBase []byte, chunks [][]byte) ([]byte, error) {
+	var out []byte
+	for i, c := range chunks {
+		last := i == len(chunks)-1
+		pt, err := aead.Open(nil, stream.ChunkNonce(nonceBase, uint64(i), last), c, nil)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %v: %v", i, err)
+		}
+		out = append(out, pt...)
+	}
+	return out, nil
+}
+
+var aeads = []stream.AEADID{stream.AES256GCM, stream.ChaCha20Poly1305}
+
+func TestStream(t *testing.T) {
+	for _, scheme := range schemes.All() {
+		for _, id := range aeads {
+			testStream(t, scheme, id)
+		}
+	}
+}
+
+func testStream(t *testing.T, scheme kem.Scheme, id stream.AEADID) {
+	const numChunks = 5
+	pk, sk, err := scheme.GenerateKey()
+	test.CheckNoErr(t, err, "key generation must succeed")
+
+	var header bytes.Buffer
+	aead, nonceBase, err := stream.StreamSealWithAEAD(pk, id, &header)
+	test.CheckNoErr(t, err, "sealing must succeed")
+	if header.Len() != stream.HeaderSize(scheme) {
+		test.ReportError(t, header.Len(), stream.HeaderSize(scheme), scheme.Name(), id)
+	}
+	if header.Bytes()[0] != byte(id) {
+		test.ReportError(t, header.Bytes()[0], id, scheme.Name())
+	}
+
+	var msg []byte
+	chunks := make([][]byte, numChunks)
+	for i := range chunks {
+		pt := bytes.Repeat([]byte{byte(i)}, 100+i)
+		msg = append(msg, pt...)
+		nonce := stream.ChunkNonce(nonceBase, uint64(i), i == numChunks-1)
+		chunks[i] = aead.Seal(nil, nonce, pt, nil)
+	}
+
+	// The header may be followed by the chunks.
+	r := bytes.NewReader(append(header.Bytes(), 0xff))
+	aead2, nonceBase2, err := stream.StreamOpen(sk, r)
+	test.CheckNoErr(t, err, "opening must succeed")
+	if r.Len() != 1 {
+		test.ReportError(t, r.Len(), 1, scheme.Name())
+	}
+	got, err := open(aead2, nonceBase2, chunks)
+	test.CheckNoErr(t, err, "decryption must succeed")
+	if !bytes.Equal(got, msg) {
+		test.ReportError(t, got, msg, scheme.Name())
+	}
+
+	// Truncated, reordered and extended streams are rejected.
+	for _, bad := range [][][]byte{
+		chunks[:numChunks-1],
+		chunks[:1],
+		{chunks[1], chunks[0], chunks[2], chunks[3], chunks[4]},
+		append(append([][]byte{}, chunks...), chunks[4]),
+	} {
+		_, err = open(aead2, nonceBase2, bad)
+		test.CheckIsErr(t, err, "decryption must fail")
+	}
+
+	// A truncated header is rejected.
+	_, _, err = stream.StreamOpen(sk, bytes.NewReader(header.Bytes()[:header.Len()-1]))
+	test.CheckIsErr(t, err, "opening must fail")
+
+	// A header naming another AEAD does not decrypt the chunks.
+	for _, other := range aeads {
+		if other == id {
+			continue
+		}
+		h := append([]byte{byte(other)}, header.Bytes()[1:]...)
+		aead3, nonceBase3, err := stream.StreamOpen(sk, bytes.NewReader(h))
+		test.CheckNoErr(t, err, "opening must succeed")
+		_, err = open(aead3, nonceBase3, chunks)
+		test.CheckIsErr(t, err, "decryption must fail")
+	}
+}
+
+func TestStreamDefaultAEAD(t *testing.T) {
+	scheme := schemes.All()[0]
+	pk, _, err := scheme.GenerateKey()
+	test.CheckNoErr(t, err, "key generation must succeed")
+
+	var header bytes.Buffer
+	_, _, err = stream.StreamSeal(pk, &header)
+	test.CheckNoErr(t, err, "sealing must succeed")
+	if header.Bytes()[0] != byte(stream.AES256GCM) {
+		test.ReportError(t, header.Bytes()[0], stream.AES256GCM)
+	}
+}
+
+func TestUnknownAEAD(t *testing.T) {
+	scheme := schemes.All()[0]
+	pk, sk, err := scheme.GenerateKey()
+	test.CheckNoErr(t, err, "key generation must succeed")
+
+	var header bytes.Buffer
+	for _, id := range []stream.AEADID{0, stream.ChaCha20Poly1305 + 1, 0xff} {
+		_, _, err = stream.StreamSealWithAEAD(pk, id, &header)
+		if err != stream.ErrUnknownAEAD {
+			test.ReportError(t, err, stream.ErrUnknownAEAD, id)
+		}
+		if header.Len() != 0 {
+			test.ReportError(t, header.Len(), 0, id)
+		}
+	}
+
+	_, _, err = stream.StreamSeal(pk, &header)
+	test.CheckNoErr(t, err, "sealing must succeed")
+	for _, id := range []byte{0, byte(stream.ChaCha20Poly1305 + 1), 0xff} {
+		h := append([]byte{id}, header.Bytes()[1:]...)
+		_, _, err = stream.StreamOpen(sk, bytes.NewReader(h))
+		if err != stream.ErrUnknownAEAD {
+			test.ReportError(t, err, stream.ErrUnknownAEAD, id)
+		}
+	}
+}
+
+func TestChunkNonce(t *testing.T) {
+	base := make([]byte, stream.NonceSize)
+	seen := make(map[string]bool)
+	for _, counter := range []uint64{0, 1, 2, 1 << 32, 1<<64 - 1} {
+		for _, last := range []bool{false, true} {
+			n := string(stream.ChunkNonce(base, counter, last))
+			if seen[n] {
+				test.ReportError(t, true, false, counter, last)
+			}
+			seen[n] = true
+		}
+	}
+	err := test.CheckPanic(func() { stream.ChunkNonce(base[1:], 0, false) })
+	test.CheckNoErr(t, err, "short nonce base must panic")
+}
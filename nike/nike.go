@@ -0,0 +1,78 @@
+// Package nike provides a unified interface for non-interactive key exchange
+// (NIKE) schemes, such as Diffie-Hellman.
+//
+// A register of schemes is available in the package
+//
+//  github.com/cloudflare/circl/nike/schemes
+package nike
+
+import (
+	"encoding"
+	"errors"
+)
+
+// A NIKE public key
+type PublicKey interface {
+	// Returns the scheme for this public key
+	Scheme() Scheme
+
+	encoding.BinaryMarshaler
+	Equal(PublicKey) bool
+}
+
+// A NIKE private key
+type PrivateKey interface {
+	// Returns the scheme for this private key
+	Scheme() Scheme
+
+	encoding.BinaryMarshaler
+	Equal(PrivateKey) bool
+}
+
+// A Scheme represents a specific instance of a NIKE.
+type Scheme interface {
+	// Name of the scheme
+	Name() string
+
+	// GenerateKey creates a new key pair.
+	GenerateKey() (PublicKey, PrivateKey, error)
+
+	// DeriveSecret returns the secret shared by the owners of the private
+	// key sk and of the private key of pk.
+	//
+	// It returns ErrPubKey if pk would make the shared secret independent
+	// of sk, for instance, if it is a low-order point.
+	DeriveSecret(sk PrivateKey, pk PublicKey) ([]byte, error)
+
+	// Unmarshals a PublicKey from the provided buffer.
+	UnmarshalBinaryPublicKey([]byte) (PublicKey, error)
+
+	// Unmarshals a PrivateKey from the provided buffer.
+	UnmarshalBinaryPrivateKey([]byte) (PrivateKey, error)
+
+	// Size of shared secrets.
+	SharedKeySize() int
+
+	// Size of packed private keys.
+	PrivateKeySize() int
+
+	// Size of packed public keys.
+	PublicKeySize() int
+}
+
+var (
+	// ErrTypeMismatch is the error used if types of, for instance, private
+	// and public keys don't match
+	ErrTypeMismatch = errors.New("types mismatch")
+
+	// ErrPubKeySize is the error used if the provided public key is of
+	// the wrong size.
+	ErrPubKeySize = errors.New("wrong size for public key")
+
+	// ErrPrivKeySize is the error used if the provided private key is of
+	// the wrong size.
+	ErrPrivKeySize = errors.New("wrong size for private key")
+
+	// ErrPubKey is the error used if the provided public key is invalid.
+	ErrPubKey = errors.New("invalid public key")
+)
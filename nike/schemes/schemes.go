@@ -0,0 +1,33 @@
+// Package schemes contains a register of NIKE schemes.
+package schemes
+
+import (
+	"strings"
+
+	"github.com/cloudflare/circl/dh/x25519"
+	"github.com/cloudflare/circl/nike"
+)
+
+var allSchemes = [...]nike.Scheme{
+	x25519.Scheme,
+}
+
+var allSchemeNames map[string]nike.Scheme
+
+func init() {
+	allSchemeNames = make(map[string]nike.Scheme)
+	for _, scheme := range allSchemes {
+		allSchemeNames[strings.ToLower(scheme.Name())] = scheme
+	}
+}
+
+// ByName returns the scheme with the given name and nil if it is not
+// supported.
+//
+// Names are case insensitive.
+func ByName(name string) nike.Scheme {
+	return allSchemeNames[strings.ToLower(name)]
+}
+
+// All returns all NIKE schemes supported.
+func All() []nike.Scheme { a := allSchemes; return a[:] }
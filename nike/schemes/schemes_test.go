@@ -0,0 +1,104 @@
+package schemes_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudflare/circl/nike/schemes"
+)
+
+func TestCaseSensitivity(t *testing.T) {
+	if schemes.ByName("x25519") != schemes.ByName("X25519") {
+		t.Fatal()
+	}
+}
+
+func BenchmarkDeriveSecret(b *testing.B) {
+	allSchemes := schemes.All()
+	for _, scheme := range allSchemes {
+		scheme := scheme
+		pk, _, _ := scheme.GenerateKey()
+		_, sk, _ := scheme.GenerateKey()
+		b.Run(scheme.Name(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = scheme.DeriveSecret(sk, pk)
+			}
+		})
+	}
+}
+
+func TestApi(t *testing.T) {
+	allSchemes := schemes.All()
+	for _, scheme := range allSchemes {
+		scheme := scheme
+		t.Run(scheme.Name(), func(t *testing.T) {
+			if scheme == nil {
+				t.Fatal()
+			}
+
+			pkA, skA, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal()
+			}
+			pkB, skB, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal()
+			}
+
+			packedPk, err := pkA.MarshalBinary()
+			if err != nil {
+				t.Fatal()
+			}
+			if len(packedPk) != scheme.PublicKeySize() {
+				t.Fatal()
+			}
+			packedSk, err := skA.MarshalBinary()
+			if err != nil {
+				t.Fatal()
+			}
+			if len(packedSk) != scheme.PrivateKeySize() {
+				t.Fatal()
+			}
+
+			pkA2, err := scheme.UnmarshalBinaryPublicKey(packedPk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pkA.Equal(pkA2) || pkA.Equal(pkB) {
+				t.Fatal()
+			}
+			skA2, err := scheme.UnmarshalBinaryPrivateKey(packedSk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !skA.Equal(skA2) || skA.Equal(skB) {
+				t.Fatal()
+			}
+			if pkA.Scheme() != scheme || skA.Scheme() != scheme {
+				t.Fatal()
+			}
+
+			ssA, err := scheme.DeriveSecret(skA2, pkB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ssB, err := scheme.DeriveSecret(skB, pkA2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ssA) != scheme.SharedKeySize() {
+				t.Fatal()
+			}
+			if !bytes.Equal(ssA, ssB) {
+				t.Fatal()
+			}
+
+			if _, err = scheme.UnmarshalBinaryPublicKey(packedPk[1:]); err == nil {
+				t.Fatal()
+			}
+			if _, err = scheme.UnmarshalBinaryPrivateKey(packedSk[1:]); err == nil {
+				t.Fatal()
+			}
+		})
+	}
+}
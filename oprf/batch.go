@@ -0,0 +1,45 @@
+package oprf
+
+import (
+	"errors"
+
+	"github.com/cloudflare/circl/oprf/group"
+)
+
+// FinalizeBatch finalizes each evaluation es[i] of the request crs[i] as
+// Finalize does, and returns the outputs in the same order. The blinds of
+// all requests are inverted at once with group.BatchInvert, which is much
+// faster than inverting them one by one for large batches. All requests
+// must use the same suite.
+func FinalizeBatch(crs []*ClientRequest, es []*Evaluation, info []byte) ([][]byte, error) {
+	if len(crs) != len(es) {
+		return nil, errors.New("the numbers of requests and evaluations differ")
+	}
+	if len(crs) == 0 {
+		return nil, nil
+	}
+
+	blinds := make([]*group.Scalar, len(crs))
+	for i, cr := range crs {
+		if cr.suite.Identifier() != crs[0].suite.Identifier() {
+			return nil, errors.New("the requests use different suites")
+		}
+		blinds[i] = cr.token.blind
+	}
+	invs, err := group.BatchInvert(blinds)
+	if err != nil {
+		return nil, err
+	}
+
+	outs := make([][]byte, len(crs))
+	for i, cr := range crs {
+		inv := invs[i]
+		outs[i], err = cr.finalize(es[i], func(p *group.Element) *group.Element {
+			return p.ScalarMult(inv)
+		}, info, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outs, nil
+}
@@ -0,0 +1,331 @@
+package group
+
+import (
+	"crypto/elliptic"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/cloudflare/circl/ecc/goldilocks"
+	"github.com/cloudflare/circl/internal/expander"
+	fp "github.com/cloudflare/circl/math/fp448"
+)
+
+// decaf448ElementLength is the length in bytes of encoded decaf448 elements.
+const decaf448ElementLength = 56
+
+// decaf448Curve is the decaf448 prime-order group, built as a quotient of the
+// Goldilocks curve by its 4-torsion subgroup. It implements the
+// elliptic.Curve interface, so that it can be used in the same way as the NIST
+// curves. Elements are stored as the affine coordinates of a canonical
+// representative, that is, the point obtained by decoding the element
+// encoding. Following the convention of crypto/elliptic, the identity is
+// represented as (0,0).
+//
+// Reference: https://datatracker.ietf.org/doc/draft-irtf-cfrg-ristretto255-decaf448/
+type decaf448Curve struct {
+	params *elliptic.CurveParams
+}
+
+var (
+	decaf448     decaf448Curve
+	decaf448Once sync.Once
+
+	// decafD is -39081 mod p.
+	decafD *big.Int
+	// decafSqrtMinusD is the non-negative square root of -d.
+	decafSqrtMinusD *big.Int
+	// decafInvSqrtMinusD is the inverse of decafSqrtMinusD.
+	decafInvSqrtMinusD *big.Int
+	// decafOneMinusD is 1-d.
+	decafOneMinusD *big.Int
+	// decafOneMinusTwoD is 1-2d.
+	decafOneMinusTwoD *big.Int
+)
+
+func initDecaf448() {
+	p := fp.P()
+	params := &elliptic.CurveParams{Name: "decaf448", BitSize: 448}
+	params.P = leBytes2Int(p[:])
+	params.N, _ = new(big.Int).SetString("3fffffffffffffffffffffffffffffffffffffffffffffffffffffff7cca23e9c44edb49aed63690216cc2728dc58f552378c292ab5844f3", 16)
+
+	decafD = new(big.Int).Sub(params.P, big.NewInt(39081))
+	params.B = decafD
+
+	decafSqrtMinusD = fpSqrt(big.NewInt(39081), params.P)
+	decafInvSqrtMinusD = new(big.Int).ModInverse(decafSqrtMinusD, params.P)
+	decafOneMinusD = big.NewInt(39082)
+	decafOneMinusTwoD = big.NewInt(78163)
+
+	decaf448.params = params
+	gen, _ := hex.DecodeString("6666666666666666666666666666666666666666666666666666666633333333333333333333333333333333333333333333333333333333")
+	params.Gx, params.Gy, _ = decaf448.decode(gen)
+}
+
+// getDecaf448 returns an elliptic.Curve implementing the decaf448 group.
+func getDecaf448() elliptic.Curve {
+	decaf448Once.Do(initDecaf448)
+	return decaf448
+}
+
+func isDecaf448(c elliptic.Curve) bool {
+	_, ok := c.(decaf448Curve)
+	return ok
+}
+
+// Params returns the parameters of the group.
+func (c decaf448Curve) Params() *elliptic.CurveParams { return c.params }
+
+// IsOnCurve reports whether (x,y) is the canonical representative of a
+// decaf448 element different from the identity.
+func (c decaf448Curve) IsOnCurve(x, y *big.Int) bool {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	P, err := c.toPoint(x, y)
+	if err != nil {
+		return false
+	}
+	cx, cy := c.fromPoint(P)
+	return cx.Cmp(x) == 0 && cy.Cmp(y) == 0
+}
+
+// Add returns the sum of (x1,y1) and (x2,y2).
+func (c decaf448Curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	P, err := c.toPoint(x1, y1)
+	if err != nil {
+		return new(big.Int), new(big.Int)
+	}
+	Q, err := c.toPoint(x2, y2)
+	if err != nil {
+		return new(big.Int), new(big.Int)
+	}
+	return c.fromPoint(goldilocks.Curve{}.Add(P, Q))
+}
+
+// Double returns 2*(x,y).
+func (c decaf448Curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	P, err := c.toPoint(x1, y1)
+	if err != nil {
+		return new(big.Int), new(big.Int)
+	}
+	return c.fromPoint(goldilocks.Curve{}.Double(P))
+}
+
+// ScalarMult returns k*(x,y) where k is a number in big-endian form.
+func (c decaf448Curve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	P, err := c.toPoint(x1, y1)
+	if err != nil {
+		return new(big.Int), new(big.Int)
+	}
+	s := c.toScalar(k)
+	return c.fromPoint(goldilocks.Curve{}.ScalarMult(&s, P))
+}
+
+// ScalarBaseMult returns k*G, where G is the generator of the group and k is
+// a number in big-endian form.
+func (c decaf448Curve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	// G is the class of 2B, where B is the base point of Ed448, so k*G is
+	// computed as (2k)*B.
+	k2 := new(big.Int).Lsh(new(big.Int).SetBytes(k), 1)
+	s := c.toScalar(k2.Bytes())
+	return c.fromPoint(goldilocks.Curve{}.ScalarBaseMult(&s))
+}
+
+// toScalar reduces a big-endian integer modulo the group order.
+func (c decaf448Curve) toScalar(k []byte) (s goldilocks.Scalar) {
+	n := new(big.Int).SetBytes(k)
+	n.Mod(n, c.params.N)
+	copy(s[:], int2LeBytes(n, goldilocks.ScalarSize))
+	return s
+}
+
+// toPoint converts the affine coordinates to a point on the Goldilocks curve.
+func (c decaf448Curve) toPoint(x, y *big.Int) (*goldilocks.Point, error) {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return goldilocks.Curve{}.Identity(), nil
+	}
+	var X, Y fp.Elt
+	copy(X[:], int2LeBytes(x, fp.Size))
+	copy(Y[:], int2LeBytes(y, fp.Size))
+	return goldilocks.FromAffine(&X, &Y)
+}
+
+// fromPoint returns the affine coordinates of the canonical representative
+// of the element represented by P.
+func (c decaf448Curve) fromPoint(P *goldilocks.Point) (x, y *big.Int) {
+	X, Y := P.ToAffine()
+	x, y, _ = c.decode(c.encode(leBytes2Int(X[:]), leBytes2Int(Y[:])))
+	return x, y
+}
+
+// encode returns the encoding of the element represented by the point (x,y).
+func (c decaf448Curve) encode(x, y *big.Int) []byte {
+	p := c.params.P
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return make([]byte, decaf448ElementLength)
+	}
+	t := mulMod(p, x, y)
+
+	u1 := mulMod(p, new(big.Int).Add(x, t), new(big.Int).Sub(x, t))
+	_, invSqrt := sqrtRatioM1(one, mulMod(p, u1, decafOneMinusD, x, x), p)
+	ratio := ctAbs(mulMod(p, invSqrt, u1, decafSqrtMinusD), p)
+	u2 := new(big.Int).Sub(mulMod(p, decafInvSqrtMinusD, ratio), t)
+	s := ctAbs(mulMod(p, decafOneMinusD, invSqrt, x, u2), p)
+
+	return int2LeBytes(s, decaf448ElementLength)
+}
+
+// decode returns the canonical representative of an encoded element.
+func (c decaf448Curve) decode(in []byte) (x, y *big.Int, err error) {
+	p := c.params.P
+	errDecode := errors.New("invalid deserialization")
+	if len(in) != decaf448ElementLength {
+		return nil, nil, errDecode
+	}
+	s := leBytes2Int(in)
+	if s.Cmp(p) >= 0 || isNegative(s) {
+		return nil, nil, errDecode
+	}
+	if s.Sign() == 0 {
+		return new(big.Int), new(big.Int), nil
+	}
+
+	ss := mulMod(p, s, s)
+	u1 := new(big.Int).Add(one, ss)
+	u2 := new(big.Int).Sub(mulMod(p, u1, u1), mulMod(p, big.NewInt(4), decafD, ss))
+	wasSquare, invSqrt := sqrtRatioM1(one, mulMod(p, u2, u1, u1), p)
+	if !wasSquare {
+		return nil, nil, errDecode
+	}
+	u3 := ctAbs(mulMod(p, two, s, invSqrt, u1, decafSqrtMinusD), p)
+	x = mulMod(p, u3, invSqrt, u2, decafInvSqrtMinusD)
+	y = mulMod(p, new(big.Int).Sub(one, ss), invSqrt, u1)
+
+	return x, y, nil
+}
+
+// elligator maps a field element to a point on the Goldilocks curve.
+func (c decaf448Curve) elligator(r0 *big.Int) (*goldilocks.Point, error) {
+	p := c.params.P
+	r := new(big.Int).Neg(mulMod(p, r0, r0))
+	rMinusOne := new(big.Int).Sub(r, one)
+	u0 := mulMod(p, decafD, rMinusOne)
+	u1 := mulMod(p, new(big.Int).Add(u0, one), new(big.Int).Sub(u0, r))
+	n := mulMod(p, new(big.Int).Add(r, one), decafOneMinusTwoD)
+
+	isSquare, invSqrt := sqrtRatioM1(one, mulMod(p, u1, n), p)
+	e := invSqrt
+	if !isSquare {
+		e = mulMod(p, invSqrt, r0)
+	}
+
+	// s = +-|n*e|, being non-negative only if n*u1 is a square.
+	s := ctAbs(mulMod(p, n, e), p)
+	if !isSquare {
+		s = new(big.Int).Sub(p, s)
+		s.Mod(s, p)
+	}
+
+	// t = -+n*(r-1)*((1-2d)*e)^2 - 1.
+	t := mulMod(p, decafOneMinusTwoD, e)
+	t = mulMod(p, n, rMinusOne, t, t)
+	if isSquare {
+		t.Neg(t)
+	}
+	t.Sub(t, one)
+
+	// (s,t) is a point on the Jacobi quartic, which is mapped to
+	// (2s/(1+s^2), (1-s^2)/t).
+	ss := mulMod(p, s, s)
+	x := mulMod(p, two, s, new(big.Int).ModInverse(new(big.Int).Add(one, ss), p))
+	y := mulMod(p, new(big.Int).Sub(one, ss), new(big.Int).ModInverse(t, p))
+
+	return c.toPoint(x, y)
+}
+
+type decafHasher struct {
+	suite *Ciphersuite
+}
+
+// Hash hashes a byte array into an Element using the one-way map of decaf448
+// on 112 uniform bytes produced by expand_message_xmd with SHA-512.
+func (h decafHasher) Hash(in []byte) (*Element, error) {
+	p := decaf448.params.P
+	b := expander.XMD(sha512.New, in, h.suite.dst, 2*decaf448ElementLength)
+	r0 := new(big.Int).Mod(leBytes2Int(b[:decaf448ElementLength]), p)
+	r1 := new(big.Int).Mod(leBytes2Int(b[decaf448ElementLength:]), p)
+
+	P0, err := decaf448.elligator(r0)
+	if err != nil {
+		return nil, err
+	}
+	P1, err := decaf448.elligator(r1)
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewElement(h.suite.Curve)
+	e.x, e.y = decaf448.fromPoint(goldilocks.Curve{}.Add(P0, P1))
+
+	return e, nil
+}
+
+// sqrtRatioM1 returns whether u/v is a square, and the non-negative value
+// r = u*(u*v)^((p-3)/4), which is sqrt(u/v) when it is a square.
+func sqrtRatioM1(u, v, p *big.Int) (bool, *big.Int) {
+	exp := new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(3)), 2)
+	r := new(big.Int).Exp(mulMod(p, u, v), exp, p)
+	r = mulMod(p, u, r)
+	check := mulMod(p, v, r, r)
+	wasSquare := check.Cmp(new(big.Int).Mod(u, p)) == 0
+
+	return wasSquare, ctAbs(r, p)
+}
+
+// fpSqrt returns the non-negative square root of a square x modulo p, where
+// p = 3 mod 4.
+func fpSqrt(x, p *big.Int) *big.Int {
+	exp := new(big.Int).Rsh(new(big.Int).Add(p, one), 2)
+	return ctAbs(new(big.Int).Exp(x, exp, p), p)
+}
+
+// isNegative reports whether the reduced x is odd.
+func isNegative(x *big.Int) bool { return x.Bit(0) == 1 }
+
+// ctAbs returns x mod p if it is non-negative, and -x mod p otherwise.
+func ctAbs(x, p *big.Int) *big.Int {
+	z := new(big.Int).Mod(x, p)
+	negZ := new(big.Int).Sub(p, z)
+	negZ.Mod(negZ, p)
+	return cMov(z, negZ, big.NewInt(int64(z.Bit(0))))
+}
+
+// mulMod returns the product of all the given values modulo p.
+func mulMod(p *big.Int, x ...*big.Int) *big.Int {
+	z := big.NewInt(1)
+	for i := range x {
+		z.Mul(z, x[i])
+		z.Mod(z, p)
+	}
+	return z
+}
+
+func leBytes2Int(in []byte) *big.Int {
+	b := make([]byte, len(in))
+	for i := range in {
+		b[len(in)-1-i] = in[i]
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func int2LeBytes(x *big.Int, n int) []byte {
+	b := x.Bytes()
+	b = append(make([]byte, n-len(b)), b...)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
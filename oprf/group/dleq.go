@@ -0,0 +1,56 @@
+package group
+
+import "encoding/binary"
+
+// Proof is a non-interactive proof of equality of discrete logarithms
+// (DLEQ), that is, a proof that log_A(kA) = log_B(kB) for some Elements A,
+// B, kA and kB. It is given by the pair of Scalars (C, S).
+type Proof struct {
+	C, S *Scalar
+}
+
+// ProveDLEQ proves that kA = k*A and kB = k*B for the Scalar k, without
+// revealing k. The transcript is hashed along with the Elements, and
+// must be passed again to VerifyDLEQ.
+//
+// The challenge is computed as in the VOPRF draft, in which A is the
+// generator and the transcript is the domain separation string of the
+// challenge. In particular, A is not hashed, so protocols in which A is not
+// fixed must commit to it in the transcript.
+func (c *Ciphersuite) ProveDLEQ(k *Scalar, A, B, kA, kB *Element, transcript []byte) *Proof {
+	r := c.RandomScalar()
+	t2 := A.ScalarMult(r)
+	t3 := B.ScalarMult(r)
+	ch := c.challengeDLEQ(B, kA, kB, t2, t3, transcript)
+
+	return &Proof{C: ch, S: r.Sub(ch.Mul(k))}
+}
+
+// VerifyDLEQ returns whether p proves that log_A(kA) = log_B(kB), for the
+// transcript given to ProveDLEQ.
+func (c *Ciphersuite) VerifyDLEQ(p *Proof, A, B, kA, kB *Element, transcript []byte) bool {
+	// Recomputes the commitments as t2 = s*A + c*kA and t3 = s*B + c*kB.
+	t2 := A.ScalarMult(p.S).Add(kA.ScalarMult(p.C))
+	t3 := B.ScalarMult(p.S).Add(kB.ScalarMult(p.C))
+
+	return c.challengeDLEQ(B, kA, kB, t2, t3, transcript).Equal(p.C)
+}
+
+// challengeDLEQ computes the challenge of a DLEQ proof from the
+// commitments t2 and t3, as in draft-05 of VOPRF.
+func (c *Ciphersuite) challengeDLEQ(B, kA, kB, t2, t3 *Element, transcript []byte) *Scalar {
+	lenBuf := make([]byte, 2)
+	var in []byte
+	for _, data := range [][]byte{
+		kA.Serialize(), B.Serialize(), kB.Serialize(),
+		t2.Serialize(), t3.Serialize(), transcript,
+	} {
+		binary.BigEndian.PutUint16(lenBuf, uint16(len(data)))
+		in = append(in, lenBuf...)
+		in = append(in, data...)
+	}
+
+	// Draft-05 hashes to a scalar with an empty domain separation tag, the
+	// domain separation is given by the transcript in the input instead.
+	return c.HashToScalar(in, nil)
+}
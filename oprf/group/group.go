@@ -5,18 +5,29 @@ import (
 	"crypto/subtle"
 	"errors"
 	"math/big"
+	"math/bits"
+	"sync/atomic"
 )
 
+var errShortBuffer = errors.New("buffer too small")
+
 // Element is a representation of a group element.
+//
+// Operations on Elements return new Elements, so only Deserialize modifies
+// an Element.
 type Element struct {
 	c elliptic.Curve
 	x *big.Int
 	y *big.Int
+
+	// enc caches the serialization of the Element as a []byte, which is
+	// nil until Serialize is called. It is reset by Deserialize.
+	enc atomic.Value
 }
 
 // NewElement generates a new Element for the corresponding ciphersuite.
 func NewElement(c elliptic.Curve) *Element {
-	p := &Element{c, new(big.Int), new(big.Int)}
+	p := &Element{c: c, x: new(big.Int), y: new(big.Int)}
 	return p
 }
 
@@ -27,9 +38,13 @@ func (p *Element) IsValid() bool {
 
 // ScalarBaseMult multiplies the Generator by the provided Scalar value.
 // The provided 'p' should be equal to the generator.
+//
+// No table is kept for the generator here: the underlying curves already
+// multiply it with fixed-base tables that are computed once, either at
+// compile time (decaf448) or on first use (the NIST curves).
 func (p *Element) ScalarBaseMult(s *Scalar) *Element {
-	g := &Element{p.c, p.c.Params().Gx, p.c.Params().Gy}
-	if !(p.Equal(g)) {
+	g := &Element{c: p.c, x: p.c.Params().Gx, y: p.c.Params().Gy}
+	if p.ConstantTimeEqual(g) != 1 {
 		return nil
 	}
 
@@ -40,6 +55,11 @@ func (p *Element) ScalarBaseMult(s *Scalar) *Element {
 }
 
 // ScalarMult multiplies a Element by the provided Scalar value.
+//
+// The multiplication is left to the underlying curve, which doubles with
+// its own formulas internally, instead of being a ladder over Double and
+// Add: the curves of the standard library multiply in constant time, which
+// such a ladder on big.Int coordinates would not.
 func (p *Element) ScalarMult(s *Scalar) *Element {
 	q := NewElement(p.c)
 	q.x, q.y = p.c.ScalarMult(p.x, p.y, s.Serialize())
@@ -47,6 +67,18 @@ func (p *Element) ScalarMult(s *Scalar) *Element {
 	return q
 }
 
+// Blind returns the Element p blinded by the Scalar r, that is, r*p.
+func Blind(p *Element, r *Scalar) *Element {
+	return p.ScalarMult(r)
+}
+
+// Unblind returns the Element p unblinded by the Scalar r, that is,
+// (1/r)*p, so that Unblind(Blind(p, r), r) is p. The Scalar r must not be
+// zero.
+func Unblind(p *Element, r *Scalar) *Element {
+	return p.ScalarMult(r.Inv())
+}
+
 // Add performs the addition operation on the calling Element object
 // along with a separate Element provided as input.
 func (p *Element) Add(q *Element) *Element {
@@ -56,6 +88,15 @@ func (p *Element) Add(q *Element) *Element {
 	return r
 }
 
+// Double performs the doubling operation on the calling Element object.
+// It is faster than calling Add with the same Element twice.
+func (p *Element) Double() *Element {
+	r := NewElement(p.c)
+	r.x, r.y = p.c.Double(p.x, p.y)
+
+	return r
+}
+
 // Neg performs the negation operation on the calling Element object.
 func (p *Element) Neg() *Element {
 	xInv := new(big.Int).ModInverse(p.x, p.c.Params().N)
@@ -67,8 +108,71 @@ func (p *Element) Neg() *Element {
 	return r
 }
 
-// Serialize the Element into a byte slice.
+// CondNeg returns the negation of the Element if b is 1, and a copy of it if
+// b is 0. The result is selected with cMov rather than with a branch on b,
+// as needed by the sign flips of hash-to-curve and of the ristretto and
+// decaf encodings. The bit b must be 0 or 1.
+func (p *Element) CondNeg(b int) *Element {
+	q := NewElement(p.c)
+	P := p.c.Params().P
+	if isDecaf448(p.c) {
+		// -(x,y) is (-x,y) on Edwards curves, which is mapped back to the
+		// canonical representative of its class.
+		nx, ny, err := decaf448.decode(decaf448.encode(condNeg(p.x, P, 1), p.y))
+		if err != nil {
+			panic(err)
+		}
+		q.x = cMov(p.x, nx, big.NewInt(int64(b)))
+		q.y = cMov(p.y, ny, big.NewInt(int64(b)))
+		return q
+	}
+
+	q.x.Set(p.x)
+	q.y = condNeg(p.y, P, b)
+	return q
+}
+
+// Serialize the Element into a byte slice. The identity is serialized as a
+// single zero byte. Elements of decaf448 use the decaf encoding instead, in
+// which the identity is serialized as zeros.
+//
+// The serialization is computed once and cached, so that serializing the
+// same Element repeatedly, for example, a public key, is cheap.
 func (p *Element) Serialize() []byte {
+	enc, _ := p.enc.Load().([]byte)
+	if enc == nil {
+		enc = p.serialize()
+		p.enc.Store(enc)
+	}
+
+	return append([]byte{}, enc...)
+}
+
+// SerializeInto writes the serialization of the Element, as returned by
+// Serialize, into buf, and returns the number of bytes written. It returns
+// an error if buf is too small. Once the serialization is cached, it does
+// not allocate.
+func (p *Element) SerializeInto(buf []byte) (int, error) {
+	enc, _ := p.enc.Load().([]byte)
+	if enc == nil {
+		enc = p.serialize()
+		p.enc.Store(enc)
+	}
+	if len(buf) < len(enc) {
+		return 0, errShortBuffer
+	}
+
+	return copy(buf, enc), nil
+}
+
+func (p *Element) serialize() []byte {
+	if isDecaf448(p.c) {
+		return decaf448.encode(p.x, p.y)
+	}
+	if p.IsIdentity() {
+		return []byte{0x00}
+	}
+
 	x := p.x.Bytes()
 	// append zeroes to the front if the bytes are not filled up.
 	x = append(make([]byte, ((p.c.Params().BitSize+7)/8)-len(x)), x...)
@@ -86,9 +190,51 @@ func (p *Element) Serialize() []byte {
 
 // Deserialize a byte array into a valid Element object.
 func (p *Element) Deserialize(in []byte) error {
+	return p.deserialize(in, true)
+}
+
+// DeserializeUnchecked is like Deserialize, but skips the checks that the
+// decoded coordinates are reduced and that the point is on the curve. It
+// still returns an error for inputs that cannot be decoded, for instance,
+// of the wrong length. Elements of decaf448 are decoded as in Deserialize,
+// since their decoding checks them anyway.
+//
+// Warning: it must only be used with inputs that were serialized from valid
+// Elements and stored where they cannot be tampered with, for instance,
+// the key of a server read from its own storage. Operating on an invalid
+// point, such as one of a twist of the curve, may leak the scalars it is
+// multiplied with. Use Deserialize for any input received from a peer.
+func (p *Element) DeserializeUnchecked(in []byte) error {
+	return p.deserialize(in, false)
+}
+
+// deserialize sets p to the Element serialized in, and checks that it is
+// valid if check is true.
+func (p *Element) deserialize(in []byte, check bool) error {
+	p.enc.Store([]byte(nil))
+	if isDecaf448(p.c) {
+		x, y, err := decaf448.decode(in)
+		if err != nil {
+			return err
+		}
+		p.x, p.y = x, y
+		return nil
+	}
+	if len(in) == 1 && in[0] == 0x00 {
+		p.x = new(big.Int)
+		p.y = new(big.Int)
+		return nil
+	}
+	if len(in) != 1+(p.c.Params().BitSize+7)/8 || (in[0] != 2 && in[0] != 3) {
+		return errors.New("invalid deserialization")
+	}
+
 	order := p.c.Params().P
 	var y2 *big.Int
 	x := new(big.Int).SetBytes(in[1:])
+	if check && x.Cmp(order) >= 0 {
+		return errors.New("invalid deserialization")
+	}
 
 	x2 := new(big.Int).Exp(x, two, order)
 	x2a := new(big.Int).Add(x2, big.NewInt(-3))
@@ -110,18 +256,36 @@ func (p *Element) Deserialize(in []byte) error {
 	p.x = new(big.Int).Mod(x, order)
 	p.y = new(big.Int).Mod(y, order)
 
-	if !p.IsValid() {
+	if check && !p.IsValid() {
 		return errors.New("invalid deserialization")
 	}
 
 	return nil
 }
 
+// IsIdentity returns a bool indicating whether the Element is the identity
+// (the point at infinity).
+func (p *Element) IsIdentity() bool {
+	return p.x.Sign() == 0 && p.y.Sign() == 0
+}
+
 // Equal returns a bool indicating whether two Elements are equal.
 func (p *Element) Equal(q *Element) bool {
 	return (p.x.Cmp(q.x) == 0) && (p.y.Cmp(q.y) == 0)
 }
 
+// ConstantTimeEqual returns 1 if the Elements p and q are equal, and 0
+// otherwise. Unlike Equal, the comparison takes time independent of the
+// coordinates of the Elements, up to the conversion of the big.Int
+// coordinates into bytes.
+func (p *Element) ConstantTimeEqual(q *Element) int {
+	n := (p.c.Params().BitSize + 7) / 8
+	a := append(padBytes(p.x, n), padBytes(p.y, n)...)
+	b := append(padBytes(q.x, n), padBytes(q.y, n)...)
+
+	return subtle.ConstantTimeCompare(a, b)
+}
+
 // Scalar is an struct representing a field element.
 type Scalar struct {
 	c elliptic.Curve
@@ -134,30 +298,164 @@ func NewScalar(c elliptic.Curve) *Scalar {
 	return s
 }
 
+// Copy returns a new Scalar with the same value as s, which can be
+// modified, or zeroized, without affecting s.
+func (s *Scalar) Copy() *Scalar {
+	return &Scalar{s.c, new(big.Int).Set(s.x)}
+}
+
 // Set sets the scalar to a value.
 func (s *Scalar) Set(x []byte) *Scalar {
 	s.x.SetBytes(x)
 	return s
 }
 
-// Inv sets the Scalar to its multiplicative inverse.
+// SetBytesWide sets the scalar to the big-endian integer b reduced modulo
+// the group order. Unlike Deserialize, b may be of any length and needs not
+// be reduced, so it can be used to import hash outputs or wide integers.
+func (s *Scalar) SetBytesWide(b []byte) *Scalar {
+	s.x.SetBytes(b)
+	s.x.Mod(s.x, s.c.Params().N)
+	return s
+}
+
+// Inv returns the multiplicative inverse of the Scalar. As zero has no
+// inverse, the inverse of zero is defined to be zero, as computing s^(N-2)
+// would give. Callers that divide by a Scalar must then ensure it is not
+// zero; those returned by RandomScalar never are.
 func (s *Scalar) Inv() *Scalar {
 	n := s.c.Params().N
-	inv := new(big.Int).ModInverse(s.x, n)
-
 	rInv := NewScalar(s.c)
-	rInv.x.Set(inv)
+	if inv := new(big.Int).ModInverse(s.x, n); inv != nil {
+		rInv.x.Set(inv)
+	}
 
 	return rInv
 }
 
-// Serialize the Scalar into a byte slice.
+// BatchInvert returns the multiplicative inverses of the scalars, which must
+// all belong to the same group. It uses Montgomery's trick, which costs a
+// single inversion and 3(n-1) multiplications for n scalars. It returns an
+// error if any of the scalars is zero.
+func BatchInvert(scalars []*Scalar) ([]*Scalar, error) {
+	invs := make([]*Scalar, len(scalars))
+	if len(scalars) == 0 {
+		return invs, nil
+	}
+
+	// invs[i] = scalars[0] * ... * scalars[i-1]
+	c := scalars[0].c
+	n := c.Params().N
+	acc := big.NewInt(1)
+	for i, s := range scalars {
+		if s.x.Sign() == 0 {
+			return nil, errors.New("cannot invert zero")
+		}
+		invs[i] = &Scalar{c, new(big.Int).Set(acc)}
+		acc.Mul(acc, s.x)
+		acc.Mod(acc, n)
+	}
+
+	// acc = 1 / (scalars[0] * ... * scalars[i])
+	acc.ModInverse(acc, n)
+	for i := len(scalars) - 1; i >= 0; i-- {
+		invs[i].x.Mul(invs[i].x, acc)
+		invs[i].x.Mod(invs[i].x, n)
+		acc.Mul(acc, scalars[i].x)
+		acc.Mod(acc, n)
+	}
+
+	return invs, nil
+}
+
+// Mul returns the product of the Scalar and t modulo the group order.
+func (s *Scalar) Mul(t *Scalar) *Scalar {
+	r := NewScalar(s.c)
+	r.x.Mul(s.x, t.x)
+	r.x.Mod(r.x, s.c.Params().N)
+
+	return r
+}
+
+// Sub returns the difference of the Scalar and t modulo the group order.
+func (s *Scalar) Sub(t *Scalar) *Scalar {
+	r := NewScalar(s.c)
+	r.x.Sub(s.x, t.x)
+	r.x.Mod(r.x, s.c.Params().N)
+
+	return r
+}
+
+// Serialize the Scalar into a fixed-length big-endian byte slice.
 func (s *Scalar) Serialize() []byte {
-	return s.x.Bytes()
+	x := s.x.Bytes()
+	// append zeroes to the front if the bytes are not filled up.
+	return append(make([]byte, ((s.c.Params().BitSize+7)/8)-len(x)), x...)
 }
 
-// Deserialize an octet-string into a valid Scalar object.
-func (s *Scalar) Deserialize(in []byte) {
+// SerializeInto writes the serialization of the Scalar, as returned by
+// Serialize, into buf without allocating, and returns the number of bytes
+// written. It returns an error if buf is too small.
+func (s *Scalar) SerializeInto(buf []byte) (int, error) {
+	n := (s.c.Params().BitSize + 7) / 8
+	if len(buf) < n {
+		return 0, errShortBuffer
+	}
+
+	out := buf[:n]
+	for i := range out {
+		out[i] = 0
+	}
+	// Write the little-endian words of the Scalar from the end of out.
+	i := n - 1
+	for _, w := range s.x.Bits() {
+		for j := 0; j < bits.UintSize/8 && i >= 0; j++ {
+			out[i] = byte(w)
+			w >>= 8
+			i--
+		}
+	}
+
+	return n, nil
+}
+
+// Deserialize an octet-string into a valid Scalar object. It returns an
+// error if the encoding is not canonical, that is, if the integer is not
+// less than the group order.
+func (s *Scalar) Deserialize(in []byte) error {
 	byteLength := (s.c.Params().BitSize + 7) / 8
-	s.x = new(big.Int).SetBytes(in[1 : byteLength+1])
+	if len(in) != byteLength {
+		return errors.New("invalid deserialization")
+	}
+	t := &Scalar{s.c, new(big.Int).SetBytes(in)}
+	if !t.IsCanonical() {
+		return errors.New("invalid deserialization")
+	}
+	s.x = t.x
+
+	return nil
+}
+
+// IsCanonical returns a bool indicating whether the Scalar is reduced
+// modulo the group order, that is, whether it lies in [0, order). Only such
+// Scalars are accepted by Deserialize, so that every Scalar has a unique
+// encoding.
+func (s *Scalar) IsCanonical() bool {
+	return s.x.Sign() >= 0 && s.x.Cmp(s.c.Params().N) < 0
+}
+
+// Zeroize overwrites the value of the Scalar with zeros and sets it to zero.
+// Copies of the value made before, for example, by arithmetic operations or
+// Serialize, are not cleared.
+func (s *Scalar) Zeroize() {
+	w := s.x.Bits()
+	for i := range w {
+		w[i] = 0
+	}
+	s.x.SetInt64(0)
+}
+
+// Equal returns a bool indicating whether two Scalars are equal.
+func (s *Scalar) Equal(t *Scalar) bool {
+	return s.x.Cmp(t.x) == 0
 }
@@ -0,0 +1,888 @@
+package group
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/armfazh/h2c-go-ref"
+	"github.com/cloudflare/circl/internal/expander"
+	"github.com/cloudflare/circl/internal/test"
+)
+
+func TestDouble(t *testing.T) {
+	const testTimes = 1 << 6
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		for i := 0; i < testTimes; i++ {
+			p := g.ScalarMult(suite.RandomScalar())
+			got := p.Double()
+			want := p.Add(p)
+			if !got.Equal(want) {
+				test.ReportError(t, got, want, suite.Name(), p)
+			}
+		}
+	}
+}
+
+func BenchmarkElement(b *testing.B) {
+	suite, _ := NewSuite(0x0003, nil)
+	p := suite.Generator().ScalarMult(suite.RandomScalar())
+
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Add(p)
+		}
+	})
+	b.Run("Double", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Double()
+		}
+	})
+	b.Run("Serialize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.Serialize()
+		}
+	})
+	b.Run("Deserialize", func(b *testing.B) {
+		enc := p.Serialize()
+		q := NewElement(suite.Curve)
+		for i := 0; i < b.N; i++ {
+			_ = q.Deserialize(enc)
+		}
+	})
+	b.Run("DeserializeUnchecked", func(b *testing.B) {
+		enc := p.Serialize()
+		q := NewElement(suite.Curve)
+		for i := 0; i < b.N; i++ {
+			_ = q.DeserializeUnchecked(enc)
+		}
+	})
+	b.Run("SerializeInto", func(b *testing.B) {
+		var buf [1 + 32]byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = p.SerializeInto(buf[:])
+		}
+	})
+}
+
+func BenchmarkScalar(b *testing.B) {
+	suite, _ := NewSuite(0x0003, nil)
+	k := suite.RandomScalar()
+
+	b.Run("Serialize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			k.Serialize()
+		}
+	})
+	b.Run("SerializeInto", func(b *testing.B) {
+		var buf [32]byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = k.SerializeInto(buf[:])
+		}
+	})
+}
+
+func BenchmarkBatchInvert(b *testing.B) {
+	suite, _ := NewSuite(0x0003, nil)
+	scalars := make([]*Scalar, 64)
+	for i := range scalars {
+		scalars[i] = suite.RandomScalar()
+	}
+
+	b.Run("Inv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, s := range scalars {
+				s.Inv()
+			}
+		}
+	})
+	b.Run("BatchInvert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = BatchInvert(scalars)
+		}
+	})
+}
+
+// fillBytes sets buf to the big-endian encoding of x, padded with zeros.
+func fillBytes(x *big.Int, buf []byte) {
+	b := x.Bytes()
+	copy(buf[len(buf)-len(b):], b)
+}
+
+func TestSerialization(t *testing.T) {
+	const testTimes = 1 << 6
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		for i := 0; i < testTimes; i++ {
+			s := suite.RandomScalar()
+			s2 := NewScalar(suite.Curve)
+			err := s2.Deserialize(s.Serialize())
+			if err != nil || !s.Equal(s2) {
+				test.ReportError(t, s2, s, suite.Name(), err)
+			}
+
+			p := g.ScalarMult(s)
+			p2 := NewElement(suite.Curve)
+			err = p2.Deserialize(p.Serialize())
+			if err != nil || !p.Equal(p2) {
+				test.ReportError(t, p2, p, suite.Name(), err)
+			}
+			p3 := NewElement(suite.Curve)
+			err = p3.DeserializeUnchecked(p.Serialize())
+			if err != nil || !p.Equal(p3) {
+				test.ReportError(t, p3, p, suite.Name(), err)
+			}
+		}
+
+		identity := g.ScalarMult(suite.Order())
+		p := NewElement(suite.Curve)
+		err = p.Deserialize(identity.Serialize())
+		if err != nil || !p.IsIdentity() {
+			test.ReportError(t, p, identity, suite.Name(), err)
+		}
+	}
+}
+
+func TestSerializationErrors(t *testing.T) {
+	for _, id := range []uint16{0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params := suite.Curve.Params()
+		byteLen := (params.BitSize + 7) / 8
+		enc := suite.Generator().ScalarMult(suite.RandomScalar()).Serialize()
+
+		// Finds an x-coordinate that is not on the curve.
+		x := new(big.Int)
+		for {
+			x.Add(x, one)
+			x3 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+			x3.Sub(x3, new(big.Int).Mul(x, big.NewInt(3)))
+			x3.Add(x3, params.B)
+			x3.Mod(x3, params.P)
+			if new(big.Int).ModSqrt(x3, params.P) == nil {
+				break
+			}
+		}
+		offCurve := make([]byte, 1+byteLen)
+		offCurve[0] = 0x02
+		fillBytes(x, offCurve[1:])
+		largeX := make([]byte, 1+byteLen)
+		largeX[0] = 0x02
+		fillBytes(params.P, largeX[1:])
+		badTag := append([]byte{0x04}, enc[1:]...)
+
+		for _, in := range [][]byte{
+			nil,
+			enc[:len(enc)-1],
+			append(enc, 0x00),
+			offCurve,
+			largeX,
+			badTag,
+		} {
+			p := NewElement(suite.Curve)
+			test.CheckIsErr(t, p.Deserialize(in), "element deserialization must fail")
+		}
+
+		// Unchecked deserialization only fails for undecodable inputs.
+		for _, in := range [][]byte{
+			nil,
+			enc[:len(enc)-1],
+			append(enc, 0x00),
+			badTag,
+		} {
+			p := NewElement(suite.Curve)
+			test.CheckIsErr(t, p.DeserializeUnchecked(in), "element deserialization must fail")
+		}
+		p := NewElement(suite.Curve)
+		test.CheckNoErr(t, p.DeserializeUnchecked(offCurve), "unchecked deserialization must not check the curve")
+		if p.IsValid() {
+			test.ReportError(t, true, false, suite.Name())
+		}
+
+		s := suite.RandomScalar().Serialize()
+		largeS := make([]byte, byteLen)
+		fillBytes(params.N, largeS)
+		for _, in := range [][]byte{
+			nil,
+			s[:len(s)-1],
+			append(s, 0x00),
+			largeS,
+		} {
+			k := NewScalar(suite.Curve)
+			test.CheckIsErr(t, k.Deserialize(in), "scalar deserialization must fail")
+		}
+	}
+}
+
+func TestDecaf448(t *testing.T) {
+	suite, err := NewSuite(0x0002, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Multiples of the generator from the ristretto255-decaf448 draft.
+	multiples := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"6666666666666666666666666666666666666666666666666666666633333333333333333333333333333333333333333333333333333333",
+		"c898eb4f87f97c564c6fd61fc7e49689314a1f818ec85eeb3bd5514ac816d38778f69ef347a89fca817e66defdedce178c7cc709b2116e75",
+		"a0c09bf2ba7208fda0f4bfe3d0f5b29a543012306d43831b5adc6fe7f8596fa308763db15468323b11cf6e4aeb8c18fe44678f44545a69bc",
+	}
+	g := suite.Generator()
+	p := g.ScalarMult(NewScalar(suite.Curve))
+	for i := range multiples {
+		want, _ := hex.DecodeString(multiples[i])
+		got := p.Serialize()
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, i)
+		}
+		got = g.ScalarBaseMult(NewScalar(suite.Curve).Set([]byte{byte(i)})).Serialize()
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, i)
+		}
+		p = p.Add(g)
+	}
+
+	p = g.Double()
+	q := g.ScalarMult(NewScalar(suite.Curve).Set([]byte{2}))
+	if !p.Equal(q) {
+		test.ReportError(t, p, q)
+	}
+
+	// Encodings that are negative or not reduced modulo p are rejected.
+	negative := make([]byte, decaf448ElementLength)
+	negative[0] = 0x01
+	params := suite.Curve.Params()
+	large := int2LeBytes(params.P, decaf448ElementLength)
+	for _, in := range [][]byte{nil, negative, large} {
+		p := NewElement(suite.Curve)
+		test.CheckIsErr(t, p.Deserialize(in), "element deserialization must fail")
+	}
+}
+
+func TestHashToScalar(t *testing.T) {
+	msg := []byte("message")
+	dst := []byte("HashToScalar-test")
+	for _, v := range []struct {
+		id uint16
+		L  int // Length of the uniform bytes as in hash_to_field.
+	}{{0x0002, 84}, {0x0003, 48}, {0x0004, 72}, {0x0005, 98}} {
+		suite, err := NewSuite(v.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := suite.HashToScalar(msg, dst)
+		b := expander.XMD(suite.newHash, msg, dst, v.L)
+		want := new(big.Int).Mod(new(big.Int).SetBytes(b), suite.Order().x)
+		if got.x.Cmp(want) != 0 {
+			test.ReportError(t, got.x, want, suite.Name())
+		}
+		if other := suite.HashToScalar(msg, []byte("other-dst")); got.Equal(other) {
+			test.ReportError(t, other.x, got.x, suite.Name())
+		}
+	}
+}
+
+func TestScalarSetBytesWide(t *testing.T) {
+	const testTimes = 1 << 6
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		N := suite.Order().x
+		size := (suite.Curve.Params().BitSize + 7) / 8
+		for i := 0; i < testTimes; i++ {
+			// Up to three times as wide as a serialized scalar.
+			b := make([]byte, i%(3*size+1))
+			_, _ = rand.Read(b)
+			got := NewScalar(suite.Curve).SetBytesWide(b)
+			want := new(big.Int).Mod(new(big.Int).SetBytes(b), N)
+			if got.x.Cmp(want) != 0 {
+				test.ReportError(t, got.x, want, suite.Name(), b)
+			}
+			var s Scalar
+			s.c = suite.Curve
+			test.CheckNoErr(t, s.Deserialize(got.Serialize()), "wide scalar must be reduced")
+		}
+	}
+}
+
+func TestScalarIsCanonical(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		N := suite.Order().x
+		size := (suite.Curve.Params().BitSize + 7) / 8
+		for _, v := range []struct {
+			x    *big.Int
+			want bool
+		}{
+			{big.NewInt(0), true},
+			{new(big.Int).Sub(N, big.NewInt(1)), true},
+			{N, false},
+			{new(big.Int).Add(N, big.NewInt(1)), false},
+		} {
+			enc := padBytes(v.x, size)
+
+			s := NewScalar(suite.Curve).Set(enc)
+			if got := s.IsCanonical(); got != v.want {
+				test.ReportError(t, got, v.want, suite.Name(), v.x)
+			}
+			err := NewScalar(suite.Curve).Deserialize(enc)
+			if got := err == nil; got != v.want {
+				test.ReportError(t, got, v.want, suite.Name(), v.x)
+			}
+		}
+	}
+}
+
+func TestBlind(t *testing.T) {
+	const testTimes = 1 << 4
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		for i := 0; i < testTimes; i++ {
+			p := g.ScalarMult(suite.RandomScalar())
+			r := suite.RandomScalar()
+			b := Blind(p, r)
+			if b.Equal(p) {
+				test.ReportError(t, b, p, suite.Name())
+			}
+			got := Unblind(b, r)
+			if !got.Equal(p) {
+				test.ReportError(t, got, p, suite.Name())
+			}
+		}
+	}
+}
+
+func TestScalarZeroize(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := suite.RandomScalar()
+		w := s.x.Bits()
+		s.Zeroize()
+		for i := range w {
+			if w[i] != 0 {
+				test.ReportError(t, w[i], 0, suite.Name(), i)
+			}
+		}
+		zero := NewScalar(suite.Curve)
+		if !s.Equal(zero) {
+			test.ReportError(t, s.x, zero.x, suite.Name())
+		}
+	}
+}
+
+func TestCondNeg(t *testing.T) {
+	const testTimes = 1 << 4
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		for i := 0; i < testTimes; i++ {
+			p := g.ScalarMult(suite.RandomScalar())
+			if got := p.CondNeg(0); !got.Equal(p) {
+				test.ReportError(t, got, p, suite.Name())
+			}
+
+			n := p.CondNeg(1)
+			if !n.IsValid() || n.Equal(p) {
+				test.ReportError(t, n, p, suite.Name())
+			}
+			if got := p.Add(n); !got.IsIdentity() {
+				test.ReportError(t, got, "identity", suite.Name())
+			}
+			if got := n.CondNeg(1); !got.Equal(p) {
+				test.ReportError(t, got, p, suite.Name())
+			}
+		}
+	}
+}
+
+func TestGeneratorIsCopy(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params := suite.Curve.Params()
+		gx, gy := new(big.Int).Set(params.Gx), new(big.Int).Set(params.Gy)
+		n := new(big.Int).Set(params.N)
+
+		g := suite.Generator()
+		g.x.SetInt64(1)
+		g.y.SetInt64(2)
+		suite.Order().Zeroize()
+
+		g = suite.Generator()
+		if g.x.Cmp(gx) != 0 || g.y.Cmp(gy) != 0 {
+			test.ReportError(t, g, gx, suite.Name())
+		}
+		if got := suite.Order().x; got.Cmp(n) != 0 || params.N.Cmp(n) != 0 {
+			test.ReportError(t, got, n, suite.Name())
+		}
+	}
+}
+
+func TestSerializeInto(t *testing.T) {
+	const testTimes = 1 << 4
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		for i := 0; i < testTimes; i++ {
+			k := suite.RandomScalar()
+			if i == 0 {
+				k = NewScalar(suite.Curve)
+			}
+			want := k.Serialize()
+			buf := make([]byte, len(want)+1)
+			n, err := k.SerializeInto(buf)
+			if err != nil || !bytes.Equal(buf[:n], want) {
+				test.ReportError(t, buf[:n], want, suite.Name(), err)
+			}
+			_, err = k.SerializeInto(buf[:len(want)-1])
+			test.CheckIsErr(t, err, "short buffer must fail")
+
+			p := g.ScalarMult(k)
+			want = p.Serialize()
+			n, err = NewElement(suite.Curve).Add(p).SerializeInto(buf)
+			if err != nil || !bytes.Equal(buf[:n], want) {
+				test.ReportError(t, buf[:n], want, suite.Name(), err)
+			}
+			_, err = p.SerializeInto(buf[:len(want)-1])
+			test.CheckIsErr(t, err, "short buffer must fail")
+		}
+	}
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := suite.RandomScalar()
+		b := suite.RandomScalar()
+		one := NewScalar(suite.Curve).Set([]byte{1})
+		zero := NewScalar(suite.Curve)
+
+		if got := a.Mul(a.Inv()); !got.Equal(one) {
+			test.ReportError(t, got.x, one.x, suite.Name())
+		}
+		if got := a.Mul(b).Sub(b.Mul(a)); !got.Equal(zero) {
+			test.ReportError(t, got.x, zero.x, suite.Name())
+		}
+		// (a - b)*G = a*G - b*G
+		g := suite.Generator()
+		got := g.ScalarMult(a.Sub(b)).Add(g.ScalarMult(b))
+		want := g.ScalarMult(a)
+		if !got.Equal(want) {
+			test.ReportError(t, got, want, suite.Name())
+		}
+	}
+}
+
+func TestSerializationCache(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		p := g.ScalarMult(suite.RandomScalar())
+		want := p.Serialize()
+
+		// Modifying the returned bytes must not alter the cache.
+		got := p.Serialize()
+		got[0] ^= 0xff
+		got = p.Serialize()
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, suite.Name())
+		}
+
+		// The cache is shared by concurrent calls.
+		var wg sync.WaitGroup
+		q := g.Double()
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = q.Serialize()
+			}()
+		}
+		wg.Wait()
+
+		// Deserialize invalidates the cache.
+		err = p.Deserialize(q.Serialize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = p.Serialize()
+		want = q.Serialize()
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, suite.Name())
+		}
+		if !p.Equal(q) {
+			test.ReportError(t, p, q, suite.Name())
+		}
+	}
+}
+
+func TestHashToGroupP256Vectors(t *testing.T) {
+	// Test vectors of P256_XMD:SHA-256_SSWU_RO_ from Appendix J.1.1 of RFC 9380.
+	suite, err := NewSuite(0x0003, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.dst = []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_")
+
+	for _, v := range []struct {
+		msg, x, y string
+	}{
+		{
+			"",
+			"2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4",
+			"8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415",
+		},
+		{
+			"abc",
+			"0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f",
+			"5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e",
+		},
+		{
+			"abcdef0123456789",
+			"65038ac8f2b1def042a5df0b33b1f4eca6bff7cb0f9c6c1526811864e544ed80",
+			"cad44d40a656e7aff4002a8de287abc8ae0482b5ae825822bb870d6df9b56ca3",
+		},
+	} {
+		p, err := suite.HashToGroup([]byte(v.msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, _ := new(big.Int).SetString(v.x, 16)
+		y, _ := new(big.Int).SetString(v.y, 16)
+		if p.x.Cmp(x) != 0 || p.y.Cmp(y) != 0 {
+			test.ReportError(t, p, &Element{c: suite.Curve, x: x, y: y}, v.msg)
+		}
+	}
+}
+
+func TestHashToGroupReference(t *testing.T) {
+	// The NIST suites compute hash_to_field with internal/expander, so check
+	// that their outputs are those of the complete suites of h2c-go-ref.
+	for _, v := range []struct {
+		id    uint16
+		suite h2c.SuiteID
+	}{
+		{0x0003, h2c.P256_XMDSHA256_SSWU_RO_},
+		{0x0004, h2c.P384_XMDSHA512_SSWU_RO_},
+		{0x0005, h2c.P521_XMDSHA512_SSWU_RO_},
+	} {
+		suite, err := NewSuite(v.id, []byte("context"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasher, err := v.suite.Get(suite.dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, msg := range []string{"", "abc", "abcdef0123456789", string(make([]byte, 200))} {
+			p, err := suite.HashToGroup([]byte(msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			q := hasher.Hash([]byte(msg))
+			x, y := q.X().Polynomial()[0], q.Y().Polynomial()[0]
+			if p.x.Cmp(x) != 0 || p.y.Cmp(y) != 0 {
+				test.ReportError(t, p, &Element{c: suite.Curve, x: x, y: y}, suite.Name(), msg)
+			}
+		}
+	}
+}
+
+func TestHashToGroupEdgeCases(t *testing.T) {
+	for _, id := range []uint16{0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The empty input maps to a valid element, regardless of whether it
+		// is nil.
+		p, err := suite.HashToGroup(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := suite.HashToGroup([]byte{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.IsValid() || p.IsIdentity() || !p.Equal(q) {
+			test.ReportError(t, p, q, suite.Name())
+		}
+
+		// A DST of 255 bytes is used as is, while longer DSTs are first
+		// hashed as H("H2C-OVERSIZE-DST-" || DST).
+		msg := []byte("message")
+		for _, n := range []int{255, 256} {
+			dst := bytes.Repeat([]byte{'D'}, n)
+			suite.dst = dst
+			got, err := suite.HashToGroup(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h := suite.newHash()
+			_, _ = h.Write([]byte("H2C-OVERSIZE-DST-"))
+			_, _ = h.Write(dst)
+			suite.dst = h.Sum(nil)
+			want, err := suite.HashToGroup(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Equal(want) != (n > 255) {
+				test.ReportError(t, got, want, suite.Name(), n)
+			}
+		}
+	}
+}
+
+func TestDLEQ(t *testing.T) {
+	transcript := []byte("DLEQ test")
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g := suite.Generator()
+		k := NewScalar(suite.Curve).Set([]byte{0x2a})
+		B := g.ScalarMult(NewScalar(suite.Curve).Set([]byte{0x07}))
+		kA := g.ScalarMult(k)
+		kB := B.ScalarMult(k)
+
+		pi := suite.ProveDLEQ(k, g, B, kA, kB, transcript)
+		if !suite.VerifyDLEQ(pi, g, B, kA, kB, transcript) {
+			test.ReportError(t, false, true, suite.Name())
+		}
+		if suite.VerifyDLEQ(pi, g, B, kA, kB, []byte("other transcript")) {
+			test.ReportError(t, true, false, suite.Name(), "transcript")
+		}
+
+		// A proof with a wrong witness fails.
+		k2 := NewScalar(suite.Curve).Set([]byte{0x2b})
+		pi = suite.ProveDLEQ(k2, g, B, kA, kB, transcript)
+		if suite.VerifyDLEQ(pi, g, B, kA, kB, transcript) {
+			test.ReportError(t, true, false, suite.Name(), "witness")
+		}
+
+		// Neither does a proof for unequal discrete logarithms.
+		pi = suite.ProveDLEQ(k, g, B, kA, B.ScalarMult(k2), transcript)
+		if suite.VerifyDLEQ(pi, g, B, kA, B.ScalarMult(k2), transcript) {
+			test.ReportError(t, true, false, suite.Name(), "statement")
+		}
+	}
+}
+
+func TestConstantTimeEqual(t *testing.T) {
+	const testTimes = 1 << 5
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := suite.Generator()
+		identity := NewElement(suite.Curve)
+		for i := 0; i < testTimes; i++ {
+			p := g.ScalarMult(suite.RandomScalar())
+			q := NewElement(suite.Curve)
+			if err := q.Deserialize(p.Serialize()); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range []struct {
+				p, q *Element
+				want int
+			}{
+				{p, p, 1},
+				{p, q, 1},
+				{identity, identity, 1},
+				{p, p.Double(), 0},
+				{p, identity, 0},
+				{identity, p, 0},
+			} {
+				got := v.p.ConstantTimeEqual(v.q)
+				if got != v.want {
+					test.ReportError(t, got, v.want, suite.Name(), v.p, v.q)
+				}
+				if want := v.p.Equal(v.q); (got == 1) != want {
+					test.ReportError(t, got == 1, want, suite.Name(), v.p, v.q)
+				}
+			}
+		}
+	}
+}
+
+func TestRandomScalarNonZero(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byteLen := (suite.Order().x.BitLen() + 7) / 8
+
+		// The zero draw is rejected, and the next one is returned.
+		one := make([]byte, byteLen)
+		one[byteLen-1] = 1
+		rnd := io.MultiReader(bytes.NewReader(make([]byte, byteLen)), bytes.NewReader(one))
+		k := suite.randomScalar(rnd)
+		if want := NewScalar(suite.Curve).Set([]byte{1}); !k.Equal(want) {
+			test.ReportError(t, k.x, want.x, suite.Name())
+		}
+	}
+}
+
+func TestScalarInvZero(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zero := NewScalar(suite.Curve)
+		if got := zero.Inv(); !got.Equal(zero) {
+			test.ReportError(t, got.x, zero.x, suite.Name())
+		}
+		if zero.x.Sign() != 0 {
+			test.ReportError(t, zero.x, 0, suite.Name())
+		}
+
+		one := NewScalar(suite.Curve).Set([]byte{1})
+		if got := one.Inv(); !got.Equal(one) {
+			test.ReportError(t, got.x, one.x, suite.Name())
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	const goroutines = 8
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, []byte("context"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := suite.RandomScalar()
+		in := []byte("input")
+		p, err := suite.HashToGroup(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := p.ScalarMult(k).Serialize()
+
+		// Each goroutine evaluates with its own clone of the suite.
+		var wg sync.WaitGroup
+		got := make([][]byte, goroutines)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				c := suite.Clone()
+				p, err := c.HashToGroup(in)
+				if err != nil {
+					return
+				}
+				got[g] = p.ScalarMult(k).Serialize()
+			}(g)
+		}
+		wg.Wait()
+		for g := range got {
+			if !bytes.Equal(got[g], want) {
+				test.ReportError(t, got[g], want, suite.Name(), g)
+			}
+		}
+
+		// Modifying a clone does not affect the original suite.
+		c := suite.Clone()
+		if c.Identifier() != suite.Identifier() || c.Name() != suite.Name() {
+			test.ReportError(t, c.Name(), suite.Name(), id)
+		}
+		c.dst[0] ^= 0xff
+		c.Hash = "modified"
+		if suite.dst[0] == c.dst[0] || suite.Hash == c.Hash {
+			t.Fatalf("%v: clone shares mutable state", suite.Name())
+		}
+		q, err := suite.HashToGroup(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !q.Equal(p) {
+			test.ReportError(t, q, p, suite.Name())
+		}
+	}
+}
+
+func TestBatchInvert(t *testing.T) {
+	for _, id := range []uint16{0x0002, 0x0003, 0x0004, 0x0005} {
+		suite, err := NewSuite(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		one := NewScalar(suite.Curve).Set([]byte{1})
+		for _, n := range []int{0, 1, 2, 17} {
+			scalars := make([]*Scalar, n)
+			for i := range scalars {
+				scalars[i] = suite.RandomScalar()
+			}
+			invs, err := BatchInvert(scalars)
+			test.CheckNoErr(t, err, "BatchInvert failed")
+			if len(invs) != n {
+				test.ReportError(t, len(invs), n, suite.Name())
+			}
+			for i := range invs {
+				if got := scalars[i].Mul(invs[i]); !got.Equal(one) {
+					test.ReportError(t, got.x, one.x, suite.Name(), n, i)
+				}
+				if want := scalars[i].Inv(); !invs[i].Equal(want) {
+					test.ReportError(t, invs[i].x, want.x, suite.Name(), n, i)
+				}
+			}
+		}
+
+		scalars := []*Scalar{suite.RandomScalar(), NewScalar(suite.Curve), suite.RandomScalar()}
+		_, err = BatchInvert(scalars)
+		test.CheckIsErr(t, err, "inverting zero must fail")
+	}
+}
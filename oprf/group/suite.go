@@ -3,11 +3,16 @@ package group
 import (
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
+	"hash"
 	"io"
 	"math/big"
 
-	"github.com/armfazh/h2c-go-ref"
+	h2ccurve "github.com/armfazh/h2c-go-ref/curve"
+	"github.com/armfazh/h2c-go-ref/mapping"
+	"github.com/cloudflare/circl/internal/expander"
 )
 
 // Ciphersuite corresponds to the OPRF ciphersuite that is chosen. The
@@ -15,6 +20,12 @@ import (
 // performing the OPRF operations, along with the different hash function
 // definitions.
 // Should be created using NewSuite, using the appropriate string.
+//
+// A Ciphersuite holds no per-operation state: its methods only read its
+// fields, and allocate what they need on each call. Hence, it is safe for
+// concurrent use by multiple goroutines, as long as its exported fields are
+// not modified meanwhile. Workers that need to modify them, or that should
+// not share memory, can each own a copy returned by Clone.
 type Ciphersuite struct {
 	// id of the ciphersuite.
 	id uint16
@@ -42,36 +53,42 @@ func (c *Ciphersuite) Name() string {
 	return c.name
 }
 
+// Clone returns an independent copy of c. Mutable fields, like the
+// domain separation tag, are copied, while the immutable tables of the
+// curve are shared.
+func (c *Ciphersuite) Clone() *Ciphersuite {
+	d := *c
+	d.dst = append([]byte{}, c.dst...)
+	return &d
+}
+
 // Generator returns the canonical (fixed) generator for the defined group.
+// Each call returns a fresh copy, so modifying it does not affect the
+// parameters of the curve.
 func (c *Ciphersuite) Generator() *Element {
-	return &Element{c.Curve, c.Curve.Params().Gx, c.Curve.Params().Gy}
+	params := c.Curve.Params()
+	return &Element{c: c.Curve, x: new(big.Int).Set(params.Gx), y: new(big.Int).Set(params.Gy)}
 }
 
-// Order returns the order of the canonical generator in the group.
+// Order returns the order of the canonical generator in the group. Each
+// call returns a fresh copy, as Generator does.
 func (c *Ciphersuite) Order() *Scalar {
-	return &Scalar{c.Curve, c.Curve.Params().N}
+	return &Scalar{c.Curve, new(big.Int).Set(c.Curve.Params().N)}
 }
 
 func getH2CSuite(c *Ciphersuite) (HashToElement, error) {
-	var suite h2c.SuiteID
-
 	switch c.Name() {
+	case "OPRFdecaf448-SHA512-ELL2-RO":
+		return decafHasher{c}, nil
 	case "OPRFP256-SHA512-ELL2-RO":
-		suite = h2c.P256_XMDSHA256_SSWU_RO_
+		return eccHasher{c, h2ccurve.P256, -10}, nil
 	case "OPRFP384-SHA512-ELL2-RO":
-		suite = h2c.P384_XMDSHA512_SSWU_RO_
+		return eccHasher{c, h2ccurve.P384, -12}, nil
 	case "OPRFP521-SHA512-ELL2-RO":
-		suite = h2c.P521_XMDSHA512_SSWU_RO_
+		return eccHasher{c, h2ccurve.P521, -4}, nil
 	default:
 		return nil, errors.New("invalid suite")
 	}
-
-	hasher, err := suite.Get(c.dst)
-	if err != nil {
-		return nil, err
-	}
-
-	return eccHasher{c, hasher}, nil
 }
 
 // HashToElement produces a new point by hashing the input message.
@@ -79,20 +96,38 @@ type HashToElement interface {
 	Hash(msg []byte) (*Element, error)
 }
 
+// eccHasher implements the P256_XMD:SHA-256_SSWU_RO_,
+// P384_XMD:SHA-512_SSWU_RO_ and P521_XMD:SHA-512_SSWU_RO_ suites of the
+// hash-to-curve draft. The field elements are computed with hashToField,
+// and only the simplified SWU map is taken from h2c-go-ref.
 type eccHasher struct {
-	suite  *Ciphersuite
-	hasher h2c.HashToPoint
+	suite *Ciphersuite
+	// curve identifies the curve in h2c-go-ref.
+	curve h2ccurve.ID
+	// z is the Z parameter of the simplified SWU map.
+	z int
 }
 
 // Hash hashes a byte array into an Element.
 func (h eccHasher) Hash(in []byte) (*Element, error) {
-	q := h.hasher.Hash(in)
-	x := q.X().Polynomial()
-	y := q.Y().Polynomial()
-
-	p := &Element{h.suite.Curve, new(big.Int), new(big.Int)}
-	p.x.Set(x[0])
-	p.y.Set(y[0])
+	E := h.curve.Get()
+	F := E.Field()
+	sswu := mapping.NewSSWU(E, F.Elt(h.z), nil)
+
+	// The NIST curves have cofactor 1, so the sum of the mapped points is
+	// the output.
+	p := NewElement(h.suite.Curve)
+	for i, u := range h.suite.hashToField(in, 2) {
+		q := sswu.Map(F.Elt(u))
+		x := q.X().Polynomial()[0]
+		y := q.Y().Polynomial()[0]
+		if i == 0 {
+			p.x.Set(x)
+			p.y.Set(y)
+		} else {
+			p.x, p.y = h.suite.Curve.Add(p.x, p.y, x, y)
+		}
+	}
 
 	if !p.IsValid() {
 		return nil, errors.New("invalid point")
@@ -118,15 +153,62 @@ func (c *Ciphersuite) HashToGroup(in []byte) (*Element, error) {
 }
 
 // HashToScalar performs a transformation to encode bytes as a Scalar object in the
-// appropriate group.
-func (c *Ciphersuite) HashToScalar([]byte) (*Scalar, error) {
-	return &Scalar{}, nil
+// appropriate group. It implements hash_to_field of the hash-to-curve draft
+// modulo the group order, using expand_message_xmd with the hash function of
+// the suite and the domain separation tag dst.
+func (c *Ciphersuite) HashToScalar(msg, dst []byte) *Scalar {
+	N := c.Order().x
+	// L = ceil((ceil(log2(N)) + k) / 8), where k is the security level of
+	// the group, that is, half the size of its order.
+	bitLen := N.BitLen()
+	L := (bitLen + (bitLen+1)/2 + 7) / 8
+
+	b := expander.XMD(c.newHash, msg, dst, L)
+
+	return NewScalar(c.Curve).SetBytesWide(b)
+}
+
+// hashToField implements hash_to_field of the hash-to-curve draft for the
+// base field of the curve, returning count elements. Each one is computed
+// from L bytes produced by expand_message_xmd, as in HashToScalar, and
+// reduced modulo the prime of the field.
+func (c *Ciphersuite) hashToField(msg []byte, count int) []*big.Int {
+	P := c.Curve.Params().P
+	bitLen := P.BitLen()
+	L := (bitLen + (bitLen+1)/2 + 7) / 8
+
+	b := expander.XMD(c.newHash, msg, c.dst, count*L)
+
+	u := make([]*big.Int, count)
+	for i := range u {
+		u[i] = new(big.Int).SetBytes(b[i*L : (i+1)*L])
+		u[i].Mod(u[i], P)
+	}
+	return u
+}
+
+// newHash returns a new instance of the hash function of the suite.
+func (c *Ciphersuite) newHash() hash.Hash {
+	if c.Hash == "sha256" {
+		return sha256.New()
+	}
+	return sha512.New()
 }
 
 // RandomScalar samples a random scalar value from the field of scalars defined by the
-// group order.
+// group order. The returned Scalar is never zero, so it can always be
+// inverted, for instance, a blind. The buffer holding the random bytes is
+// cleared before returning, so the returned Scalar is the only copy of the
+// secret, which callers should wipe with Zeroize when it is no longer
+// needed.
 // TODO: not constant time
 func (c *Ciphersuite) RandomScalar() *Scalar {
+	return c.randomScalar(rand.Reader)
+}
+
+// randomScalar samples a non-zero scalar as RandomScalar does, with the
+// random bytes read from rnd.
+func (c *Ciphersuite) randomScalar(rnd io.Reader) *Scalar {
 	N := c.Order()
 	bitLen := N.x.BitLen()
 	byteLen := (bitLen + 7) >> 3
@@ -134,7 +216,7 @@ func (c *Ciphersuite) RandomScalar() *Scalar {
 
 	// rejection sampling
 	for {
-		_, err := io.ReadFull(rand.Reader, buf)
+		_, err := io.ReadFull(rnd, buf)
 		if err != nil {
 			panic("scalar generation failed")
 		}
@@ -142,14 +224,18 @@ func (c *Ciphersuite) RandomScalar() *Scalar {
 		var mask = []byte{0xff, 0x1, 0x3, 0x7, 0xf, 0x1f, 0x3f, 0x7f}
 		buf[0] = buf[0] & mask[bitLen%8]
 
-		// Check if scalar is in the correct range.
-		if new(big.Int).SetBytes(buf).Cmp(N.x) >= 0 {
+		// Check if scalar is in the correct range, and is not zero.
+		x := new(big.Int).SetBytes(buf)
+		if x.Cmp(N.x) >= 0 || x.Sign() == 0 {
 			continue
 		}
 		break
 	}
 
 	x := new(big.Int).SetBytes(buf)
+	for i := range buf {
+		buf[i] = 0
+	}
 	return &Scalar{c.Curve, x}
 }
 
@@ -159,6 +245,12 @@ func NewSuite(id uint16, ctx []byte) (*Ciphersuite, error) {
 	dst := []byte("VOPRF05-")
 
 	switch id {
+	case 0x0002:
+		cSuite.id = id
+		cSuite.name = "OPRFdecaf448-SHA512-ELL2-RO"
+		cSuite.dst = append(dst, ctx...)
+		cSuite.Hash = "sha512"
+		cSuite.Curve = getDecaf448()
 	case 0x0003:
 		cSuite.id = id
 		cSuite.name = "OPRFP256-SHA512-ELL2-RO"
@@ -178,7 +270,7 @@ func NewSuite(id uint16, ctx []byte) (*Ciphersuite, error) {
 		cSuite.dst = append(dst, ctx...)
 		cSuite.Hash = "sha512"
 		cSuite.Curve = elliptic.P521()
-	// TODO: support ristretto255 and decaf448
+	// TODO: support ristretto255
 	default:
 		return nil, errors.New("the chosen group is not supported")
 	}
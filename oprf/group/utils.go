@@ -32,6 +32,14 @@ func cMov(a, b, c *big.Int) *big.Int {
 	return new(big.Int).Add(m1, m2)
 }
 
+// condNeg returns -x mod p if b is 1, and x if b is 0, using cMov to
+// select the result.
+func condNeg(x, p *big.Int, b int) *big.Int {
+	neg := new(big.Int).Sub(p, x)
+	neg.Mod(neg, p)
+	return cMov(x, neg, big.NewInt(int64(b)))
+}
+
 // sgn0 returns -1 if x is negative (in little-endian sense) and 1 if x is positive
 func sgn0(x *big.Int) *big.Int {
 	m := new(big.Int).Mod(x, two)
@@ -42,3 +50,13 @@ func sgn0(x *big.Int) *big.Int {
 	sZeroCmp := equals(sign, zero)
 	return cMov(sign, one, sZeroCmp)
 }
+
+// padBytes returns the big-endian encoding of x, padded with zeros to n
+// bytes.
+func padBytes(x *big.Int, n int) []byte {
+	b := x.Bytes()
+	if len(b) >= n {
+		return b
+	}
+	return append(make([]byte, n-len(b)), b...)
+}
@@ -11,6 +11,10 @@
 //   - Setup
 //   - Evaluate
 //   - VerifyFinalize
+//
+// The verifiable mode (VOPRF) is supported with NewVerifiableServer and
+// NewVerifiableClient, where the Server proves its evaluations with
+// EvaluateWithProof and the Client checks them with FinalizeVerified.
 // References
 //  - OPRF draft: https://datatracker.ietf.org/doc/draft-irtf-cfrg-voprf/
 package oprf
@@ -30,6 +34,8 @@ import (
 type SuiteID uint16
 
 const (
+	// OPRFDecaf448 is the constant to represent the OPRF decaf448 with SHA-512 group.
+	OPRFDecaf448 SuiteID = 0x0002
 	// OPRFP256 is the constant to represent the OPRF P-256 with SHA-512 (SSWU-RO) group.
 	OPRFP256 SuiteID = 0x0003
 	// OPRFP384 is the constant to represent the OPRF P-384 with SHA-512 (SSWU-RO) group.
@@ -38,14 +44,42 @@ const (
 	OPRFP521 SuiteID = 0x0005
 )
 
+var supportedSuites = [...]SuiteID{OPRFDecaf448, OPRFP256, OPRFP384, OPRFP521}
+
+// SupportedSuites returns the IDs of all the suites supported by this
+// package, for instance, to advertise them during a negotiation.
+func SupportedSuites() []SuiteID { s := supportedSuites; return s[:] }
+
 var (
 	// OPRFMode is the context string to define a OPRF.
 	OPRFMode byte = 0x00
+	// VerifiableMode is the context string to define a VOPRF, in which the
+	// server proves that evaluations use the key committed in its public key.
+	VerifiableMode byte = 0x01
 )
 
+// versionPrefix05 is the prefix of the domain separation strings of
+// draft-05.
+const versionPrefix05 = "VOPRF05-"
+
 var (
 	// ErrUnsupportedGroup is an error stating that the ciphersuite chosen is not supported
 	ErrUnsupportedGroup = errors.New("the chosen group is not supported")
+	// ErrDegenerateEvaluation is an error stating that the server returned
+	// an evaluation that is the identity or the blinded token unchanged.
+	ErrDegenerateEvaluation = errors.New("the evaluation is degenerate")
+	// ErrNotVerifiable is an error stating that a Server or Client was not
+	// created in the verifiable mode.
+	ErrNotVerifiable = errors.New("the mode is not verifiable")
+	// ErrInvalidProof is an error stating that the proof of an evaluation
+	// failed to verify.
+	ErrInvalidProof = errors.New("the proof is invalid")
+	// ErrAmbiguousSuite is an error stating that a serialized public key
+	// belongs to several suites.
+	ErrAmbiguousSuite = errors.New("the suite is ambiguous")
+	// ErrKeyPairMismatch is an error stating that the public key of a
+	// KeyPair is not the one of its private key.
+	ErrKeyPairMismatch = errors.New("the public key does not match the private key")
 )
 
 // BlindToken corresponds to a token that has been blinded.
@@ -69,25 +103,41 @@ type Evaluation struct {
 
 // KeyPair is an struct containing a public and private key.
 type KeyPair struct {
-	pubK  *group.Element
+	pubK *group.Element
+	// PrivK is the private key. It is shared with the KeyPair, so modifying
+	// it changes the key; use PrivateKeyScalar to get a copy instead.
 	PrivK *group.Scalar
+	// suite of the keys, set when they are generated or deserialized.
+	suite *group.Ciphersuite
 }
 
 // Client is a representation of a Client during protocol execution.
+//
+// A Client holds no per-request state, so a single Client can be used
+// concurrently by multiple goroutines. Each ClientRequest carries its own
+// blind.
 type Client struct {
 	suite *group.Ciphersuite
 	ctx   []byte
+	// pkS is the public key of the server, only set in the verifiable mode.
+	pkS *group.Element
 }
 
 // Server is a representation of a Server during protocol execution.
+//
+// A Server only reads its key pair and suite, so a single Server can be
+// shared by multiple goroutines calling Evaluate, FullEvaluate and
+// VerifyFinalize concurrently, as long as Kp is not modified meanwhile.
 type Server struct {
 	suite *group.Ciphersuite
 	ctx   []byte
 	Kp    *KeyPair
 }
 
-func generateContext(id SuiteID) []byte {
-	ctx := [3]byte{OPRFMode, 0, byte(id)}
+// generateContext returns the context string I2OSP(mode, 1) ||
+// I2OSP(id, 2).
+func generateContext(mode byte, id SuiteID) []byte {
+	ctx := [3]byte{mode, byte(id >> 8), byte(id)}
 
 	return ctx[:]
 }
@@ -100,17 +150,51 @@ func (kp *KeyPair) Serialize() ([]byte, []byte) {
 	return pubK, privK
 }
 
+// PublicKey returns the serialized public key of kp.
+func (kp *KeyPair) PublicKey() []byte {
+	return kp.pubK.Serialize()
+}
+
+// PrivateKeyScalar returns a copy of the private key of kp, so modifying
+// or zeroizing it does not affect kp.
+func (kp *KeyPair) PrivateKeyScalar() *group.Scalar {
+	return kp.PrivK.Copy()
+}
+
 // Deserialize deserializes a KeyPair into an element and field element of the group.
 func (kp *KeyPair) Deserialize(suite *group.Ciphersuite, privK, pubK []byte) error {
 	priv := group.NewScalar(suite.Curve)
-	priv.Deserialize(privK)
+	err := priv.Deserialize(privK)
+	if err != nil {
+		return err
+	}
 
 	pub := group.NewElement(suite.Curve)
-	err := pub.Deserialize(pubK)
+	err = pub.Deserialize(pubK)
 	if err != nil {
 		return err
 	}
 
+	kp.PrivK = priv
+	kp.pubK = pub
+	kp.suite = suite
+
+	return nil
+}
+
+// Validate checks that the public key of kp is [PrivK]G, for the generator
+// G of the suite, so corrupted or mismatched halves of a KeyPair are
+// detected when loading it. It returns ErrKeyPairMismatch otherwise.
+func (kp *KeyPair) Validate() error {
+	if kp.suite == nil || kp.pubK == nil || kp.PrivK == nil {
+		return ErrKeyPairMismatch
+	}
+
+	pubK := kp.suite.Generator().ScalarBaseMult(kp.PrivK)
+	if pubK.ConstantTimeEqual(kp.pubK) != 1 {
+		return ErrKeyPairMismatch
+	}
+
 	return nil
 }
 
@@ -119,7 +203,7 @@ func GenerateKeyPair(suite *group.Ciphersuite) *KeyPair {
 	privK := suite.RandomScalar()
 	pubK := suite.Generator().ScalarBaseMult(privK)
 
-	return &KeyPair{pubK, privK}
+	return &KeyPair{pubK, privK, suite}
 }
 
 func assignKeyPair(suite *group.Ciphersuite, privK, pubK []byte) (*KeyPair, error) {
@@ -129,6 +213,11 @@ func assignKeyPair(suite *group.Ciphersuite, privK, pubK []byte) (*KeyPair, erro
 		return nil, err
 	}
 
+	err = kp.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	return kp, nil
 }
 
@@ -144,9 +233,48 @@ func suiteFromID(id SuiteID, ctx []byte) (*group.Ciphersuite, error) {
 	return suite, err
 }
 
+// GuessSuite returns the suite to which the serialized public key pubK
+// belongs. The suite is inferred from the length of pubK, which differs
+// among the supported suites, and pubK must be a valid element other than
+// the identity of the group of that suite. It returns ErrAmbiguousSuite if
+// pubK is valid in several suites, and ErrUnsupportedGroup if it is valid
+// in none.
+func GuessSuite(pubK []byte) (SuiteID, error) {
+	var found []SuiteID
+	for _, id := range supportedSuites {
+		suite, err := suiteFromID(id, nil)
+		if err != nil {
+			return 0, err
+		}
+		p := group.NewElement(suite.Curve)
+		if p.Deserialize(pubK) == nil && !p.IsIdentity() {
+			found = append(found, id)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return 0, ErrUnsupportedGroup
+	case 1:
+		return found[0], nil
+	default:
+		return 0, ErrAmbiguousSuite
+	}
+}
+
 // NewServer creates a new instantiation of a Server.
 func NewServer(id SuiteID) (*Server, error) {
-	ctx := generateContext(id)
+	return newServer(OPRFMode, id)
+}
+
+// NewVerifiableServer creates a new instantiation of a Server in the
+// verifiable mode, which can prove its evaluations with EvaluateWithProof.
+func NewVerifiableServer(id SuiteID) (*Server, error) {
+	return newServer(VerifiableMode, id)
+}
+
+func newServer(mode byte, id SuiteID) (*Server, error) {
+	ctx := generateContext(mode, id)
 
 	suite, err := suiteFromID(id, ctx)
 	if err != nil {
@@ -163,7 +291,17 @@ func NewServer(id SuiteID) (*Server, error) {
 // NewServerWithKeyPair creates a new instantiation of a Server. It can create
 // a server with existing keys or use pre-generated keys.
 func NewServerWithKeyPair(id SuiteID, privK, pubK []byte) (*Server, error) {
-	ctx := generateContext(id)
+	return newServerWithKeyPair(OPRFMode, id, privK, pubK)
+}
+
+// NewVerifiableServerWithKeyPair is like NewServerWithKeyPair, but creates a
+// Server in the verifiable mode.
+func NewVerifiableServerWithKeyPair(id SuiteID, privK, pubK []byte) (*Server, error) {
+	return newServerWithKeyPair(VerifiableMode, id, privK, pubK)
+}
+
+func newServerWithKeyPair(mode byte, id SuiteID, privK, pubK []byte) (*Server, error) {
+	ctx := generateContext(mode, id)
 
 	suite, err := suiteFromID(id, ctx)
 	if err != nil {
@@ -181,22 +319,41 @@ func NewServerWithKeyPair(id SuiteID, privK, pubK []byte) (*Server, error) {
 		Kp:    keyPair}, nil
 }
 
+// PublicKey returns the serialized public key of the server, which clients
+// in the verifiable mode are created with, see NewVerifiableClient.
+func (s *Server) PublicKey() []byte {
+	return s.Kp.PublicKey()
+}
+
 // Evaluate blindly signs a client token.
 func (s *Server) Evaluate(b BlindToken) (*Evaluation, error) {
-	p := group.NewElement(s.suite.Curve)
-	err := p.Deserialize(b)
+	_, z, err := s.evaluate(b)
 	if err != nil {
 		return nil, err
 	}
 
-	z := p.ScalarMult(s.Kp.PrivK)
-	ser := z.Serialize()
+	return &Evaluation{z.Serialize()}, nil
+}
+
+// evaluate returns the blinded token b as an element p, and its evaluation
+// z = k*p under the private key k.
+func (s *Server) evaluate(b BlindToken) (p, z *group.Element, err error) {
+	p = group.NewElement(s.suite.Curve)
+	err = p.Deserialize(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.IsIdentity() {
+		return nil, nil, errors.New("invalid blinded token")
+	}
 
-	return &Evaluation{ser}, nil
+	return p, p.ScalarMult(s.Kp.PrivK), nil
 }
 
-// FinalizeHash computes the final hash for the suite.
-func finalizeHash(c *group.Ciphersuite, data, iToken, info, ctx []byte) []byte {
+// FinalizeHash computes the final hash for the suite. The extraInput is
+// only hashed when it is not empty, so the output matches the draft when no
+// extra input is used.
+func finalizeHash(c *group.Ciphersuite, data, iToken, info, extraInput, ctx []byte) []byte {
 	var h hash.Hash
 	if c.Hash == "sha256" {
 		h = sha256.New()
@@ -218,7 +375,13 @@ func finalizeHash(c *group.Ciphersuite, data, iToken, info, ctx []byte) []byte {
 	_, _ = h.Write(lenBuf)
 	_, _ = h.Write(info)
 
-	dst := []byte("VOPRF05-Finalize-")
+	if len(extraInput) > 0 {
+		binary.BigEndian.PutUint16(lenBuf, uint16(len(extraInput)))
+		_, _ = h.Write(lenBuf)
+		_, _ = h.Write(extraInput)
+	}
+
+	dst := []byte(versionPrefix05 + "Finalize-")
 	dst = append(dst, ctx...)
 
 	binary.BigEndian.PutUint16(lenBuf, uint16(len(dst)))
@@ -230,6 +393,13 @@ func finalizeHash(c *group.Ciphersuite, data, iToken, info, ctx []byte) []byte {
 
 // FullEvaluate performs a full evaluation at the server side.
 func (s *Server) FullEvaluate(in, info []byte) ([]byte, error) {
+	return s.FullEvaluateWithExtraInput(in, info, nil)
+}
+
+// FullEvaluateWithExtraInput performs a full evaluation at the server side,
+// binding the output to extraInput, for example, a nonce chosen by the
+// client for the request.
+func (s *Server) FullEvaluateWithExtraInput(in, info, extraInput []byte) ([]byte, error) {
 	p, err := s.suite.HashToGroup(in)
 	if err != nil {
 		return nil, err
@@ -238,32 +408,42 @@ func (s *Server) FullEvaluate(in, info []byte) ([]byte, error) {
 	t := p.ScalarMult(s.Kp.PrivK)
 	iToken := t.Serialize()
 
-	h := finalizeHash(s.suite, in, iToken, info, s.ctx)
+	h := finalizeHash(s.suite, in, iToken, info, extraInput, s.ctx)
 
 	return h, nil
 }
 
 // VerifyFinalize verifies the evaluation.
 func (s *Server) VerifyFinalize(in, info, out []byte) bool {
+	return s.VerifyFinalizeWithExtraInput(in, info, nil, out)
+}
+
+// VerifyFinalizeWithExtraInput verifies the evaluation of an output bound
+// to extraInput. It fails if extraInput is not the one used by the client.
+func (s *Server) VerifyFinalizeWithExtraInput(in, info, extraInput, out []byte) bool {
 	p, err := s.suite.HashToGroup(in)
 	if err != nil {
 		return false
 	}
 
-	el := p.Serialize()
+	return s.verifyFinalize(in, p, info, extraInput, out)
+}
 
-	e, err := s.Evaluate(el)
-	if err != nil {
+// verifyFinalize verifies the evaluation out of the input in, already
+// mapped to the element p.
+func (s *Server) verifyFinalize(in []byte, p *group.Element, info, extraInput, out []byte) bool {
+	if p.IsIdentity() {
 		return false
 	}
 
-	h := finalizeHash(s.suite, in, e.element, info, s.ctx)
+	t := p.ScalarMult(s.Kp.PrivK)
+	h := finalizeHash(s.suite, in, t.Serialize(), info, extraInput, s.ctx)
 	return subtle.ConstantTimeCompare(h, out) == 1
 }
 
 // NewClient creates a new instantiation of a Client.
 func NewClient(id SuiteID) (*Client, error) {
-	ctx := generateContext(id)
+	ctx := generateContext(OPRFMode, id)
 
 	suite, err := suiteFromID(id, ctx)
 	if err != nil {
@@ -275,10 +455,37 @@ func NewClient(id SuiteID) (*Client, error) {
 		ctx:   ctx}, nil
 }
 
+// NewVerifiableClient creates a new instantiation of a Client in the
+// verifiable mode, which checks evaluations against the serialized public
+// key pubK of the server with FinalizeVerified.
+func NewVerifiableClient(id SuiteID, pubK []byte) (*Client, error) {
+	ctx := generateContext(VerifiableMode, id)
+
+	suite, err := suiteFromID(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pkS := group.NewElement(suite.Curve)
+	err = pkS.Deserialize(pubK)
+	if err != nil {
+		return nil, err
+	}
+	if pkS.IsIdentity() {
+		return nil, errors.New("invalid public key")
+	}
+
+	return &Client{
+		suite: suite,
+		ctx:   ctx,
+		pkS:   pkS}, nil
+}
+
 // ClientRequest is a structure to encapsulate the output of a Request call.
 type ClientRequest struct {
 	suite  *group.Ciphersuite
 	ctx    []byte
+	pkS    *group.Element
 	token  *Token
 	bToken BlindToken
 }
@@ -292,28 +499,49 @@ func (c *Client) Request(in []byte) (*ClientRequest, error) {
 		return nil, err
 	}
 
-	t := p.ScalarMult(r)
-	bToken := t.Serialize()
+	bToken := group.Blind(p, r).Serialize()
 
 	tk := &Token{in, r}
-	return &ClientRequest{c.suite, c.ctx, tk, bToken}, nil
+	return &ClientRequest{c.suite, c.ctx, c.pkS, tk, bToken}, nil
 }
 
 // Finalize computes the signed token from the server Evaluation and returns
 // the output of the OPRF protocol.
+// It returns ErrDegenerateEvaluation if the evaluated element is the identity
+// or equal to the blinded token. The prime-order groups of the supported
+// suites have no other elements of low order.
 func (cr *ClientRequest) Finalize(e *Evaluation, info []byte) ([]byte, error) {
+	return cr.FinalizeWithExtraInput(e, info, nil)
+}
+
+// FinalizeWithExtraInput is like Finalize, but binds the output to
+// extraInput, for example, a nonce chosen for the request, so that the
+// output cannot be replayed in another context. The server must use the
+// same extraInput to verify the output.
+func (cr *ClientRequest) FinalizeWithExtraInput(e *Evaluation, info, extraInput []byte) ([]byte, error) {
+	return cr.finalize(e, func(p *group.Element) *group.Element {
+		return group.Unblind(p, cr.token.blind)
+	}, info, extraInput)
+}
+
+// finalize unblinds the evaluation e with unblind and returns the output of
+// the OPRF protocol.
+func (cr *ClientRequest) finalize(e *Evaluation, unblind func(*group.Element) *group.Element, info, extraInput []byte) ([]byte, error) {
+	if subtle.ConstantTimeCompare(e.element, cr.bToken) == 1 {
+		return nil, ErrDegenerateEvaluation
+	}
+
 	p := group.NewElement(cr.suite.Curve)
 	err := p.Deserialize(e.element)
 	if err != nil {
 		return nil, err
 	}
+	if p.IsIdentity() {
+		return nil, ErrDegenerateEvaluation
+	}
 
-	r := cr.token.blind
-	rInv := r.Inv()
-
-	tt := p.ScalarMult(rInv)
-	iToken := tt.Serialize()
+	iToken := unblind(p).Serialize()
 
-	h := finalizeHash(cr.suite, cr.token.data, iToken, info, cr.ctx)
+	h := finalizeHash(cr.suite, cr.token.data, iToken, info, extraInput, cr.ctx)
 	return h, nil
 }
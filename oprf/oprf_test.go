@@ -3,9 +3,9 @@ package oprf
 import (
 	"bytes"
 	"encoding/hex"
-	"encoding/json"
-	"io/ioutil"
-	"os"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cloudflare/circl/internal/test"
@@ -160,6 +160,125 @@ func TestClientVerifyFinalize(t *testing.T) {
 	}
 }
 
+func TestConcurrentUse(t *testing.T) {
+	const goroutines = 8
+	const iterations = 4
+	for _, id := range []SuiteID{OPRFDecaf448, OPRFP256, OPRFP384, OPRFP521} {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		client, err := NewClient(id)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, goroutines)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					in := []byte{byte(g), byte(i)}
+					info := []byte("test information")
+					cr, err := client.Request(in)
+					if err != nil {
+						errs <- err
+						return
+					}
+					eval, err := srv.Evaluate(cr.bToken)
+					if err != nil {
+						errs <- err
+						return
+					}
+					h, err := cr.Finalize(eval, info)
+					if err != nil {
+						errs <- err
+						return
+					}
+					want, err := srv.FullEvaluate(in, info)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if !bytes.Equal(h, want) || !srv.VerifyFinalize(in, info, h) {
+						errs <- fmt.Errorf("suite %v: mismatch on input %x", id, in)
+						return
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClientVerifyFinalizeExtraInput(t *testing.T) {
+	srv, err := NewServer(OPRFP256)
+	if err != nil {
+		t.Fatal("invalid setup of server: " + err.Error())
+	}
+
+	client, err := NewClient(OPRFP256)
+	if err != nil {
+		t.Fatal("invalid setup of client: " + err.Error())
+	}
+
+	in := []byte{00}
+	info := []byte("test information")
+	nonce := []byte("client nonce")
+
+	cr, err := client.Request(in)
+	if err != nil {
+		t.Fatal("invalid blinding of client: " + err.Error())
+	}
+
+	eval, err := srv.Evaluate(cr.bToken)
+	if err != nil {
+		t.Fatal("invalid evaluation of server: " + err.Error())
+	}
+
+	h, err := cr.FinalizeWithExtraInput(eval, info, nonce)
+	if err != nil {
+		t.Fatal("invalid unblinding of client: " + err.Error())
+	}
+
+	if !srv.VerifyFinalizeWithExtraInput(in, info, nonce, h) {
+		t.Fatal("invalid verification with matching extra input")
+	}
+
+	full, err := srv.FullEvaluateWithExtraInput(in, info, nonce)
+	if err != nil {
+		t.Fatal("invalid full evaluation of server: " + err.Error())
+	}
+	if !bytes.Equal(full, h) {
+		t.Fatal("full evaluation does not match client output")
+	}
+
+	if srv.VerifyFinalizeWithExtraInput(in, info, []byte("other nonce"), h) {
+		t.Fatal("verification succeeded with mismatched extra input")
+	}
+	if srv.VerifyFinalize(in, info, h) {
+		t.Fatal("verification succeeded without extra input")
+	}
+
+	h0, err := cr.Finalize(eval, info)
+	if err != nil {
+		t.Fatal("invalid unblinding of client: " + err.Error())
+	}
+	h1, err := cr.FinalizeWithExtraInput(eval, info, nil)
+	if err != nil {
+		t.Fatal("invalid unblinding of client: " + err.Error())
+	}
+	if !bytes.Equal(h0, h1) {
+		t.Fatal("empty extra input changed the output")
+	}
+}
+
 type Vector struct {
 	Blind struct {
 		Blinded string `json:"BlindedElement"`
@@ -167,7 +286,11 @@ type Vector struct {
 	} `json:"Blind"`
 	Output     string `json:"ClientOutput"`
 	Evaluation struct {
-		Eval string `json:"EvaluatedElement"`
+		Eval  string `json:"EvaluatedElement"`
+		Proof struct {
+			C string `json:"c"`
+			S string `json:"s"`
+		} `json:"proof"`
 	} `json:"Evaluation"`
 	Input struct {
 		In string `json:"ClientInput"`
@@ -188,18 +311,17 @@ type Suite struct {
 	P256 Vectors `json:"BaseP256-SHA256-SSWU-RO"`
 	P384 Vectors `json:"BaseP384-SHA512-SSWU-RO"`
 	P521 Vectors `json:"BaseP521-SHA512-SSWU-RO"`
+
+	D448           Vectors `json:"Basedecaf448-SHA512-R255MAP-RO"`
+	D448Verifiable Vectors `json:"Verifiabledecaf448-SHA512-R255MAP-RO"`
+
+	P256Verifiable Vectors `json:"VerifiableP256-SHA256-SSWU-RO"`
+	P384Verifiable Vectors `json:"VerifiableP384-SHA512-SSWU-RO"`
+	P521Verifiable Vectors `json:"VerifiableP521-SHA512-SSWU-RO"`
 }
 
 func (s *Suite) readFile(t *testing.T, fileName string) {
-	jsonFile, err := os.Open(fileName)
-	if err != nil {
-		t.Fatalf("File %v can not be opened. Error: %v", fileName, err)
-	}
-	defer jsonFile.Close()
-	input, _ := ioutil.ReadAll(jsonFile)
-
-	err = json.Unmarshal(input, &s)
-	if err != nil {
+	if err := test.ReadJSON(fileName, s); err != nil {
 		t.Fatalf("File %v can not be loaded. Error: %v", fileName, err)
 	}
 }
@@ -270,6 +392,21 @@ func setUpParties(t *testing.T, name string) (*Server, *Client) {
 			t.Fatal("invalid setup of client: " + err.Error())
 		}
 
+		return srv, client
+	} else if name == "decaf448-SHA512-R255MAP-RO" {
+		srv, err := NewServer(OPRFDecaf448)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		if srv == nil {
+			t.Fatal("invalid setup of server: no server.")
+		}
+
+		client, err := NewClient(OPRFDecaf448)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
 		return srv, client
 	}
 
@@ -287,7 +424,7 @@ func blindTest(c *group.Ciphersuite, ctx []byte, v Vector) *ClientRequest {
 	bToken := t.Serialize()
 
 	token := &Token{in, s}
-	return &ClientRequest{c, ctx, token, bToken}
+	return &ClientRequest{suite: c, ctx: ctx, token: token, bToken: bToken}
 }
 
 func generateIssuedToken(c *Client, e *Evaluation, t *Token) IssuedToken {
@@ -354,3 +491,731 @@ func TestDraftVectors(t *testing.T) {
 		t.Run("ORPF-Base-Protocol", v[i].run)
 	}
 }
+
+func TestDraftVectorsDecaf448(t *testing.T) {
+	// Test vectors from draft-05. The skS of the base mode vectors does not
+	// match their evaluations, so the evaluation is checked against the
+	// verifiable mode vectors, which use the same group operation.
+	var s Suite
+
+	s.readFile(t, "testdata/vectors.json")
+	srv, client := setUpParties(t, s.D448.SuiteName)
+
+	for _, j := range s.D448.Vector {
+		cr := blindTest(client.suite, client.ctx, j)
+		testBToken, _ := hex.DecodeString(j.Blind.Blinded[2:])
+		if !bytes.Equal(testBToken, cr.bToken) {
+			test.ReportError(t, cr.bToken, testBToken, "request")
+		}
+
+		testEval, _ := hex.DecodeString(j.Evaluation.Eval[2:])
+		eval := &Evaluation{testEval}
+
+		iToken := generateIssuedToken(client, eval, cr.token)
+		testIToken, _ := hex.DecodeString(j.Unblind.IToken[2:])
+		if !bytes.Equal(testIToken, iToken) {
+			test.ReportError(t, iToken, testIToken, "unblind")
+		}
+
+		h, err := cr.Finalize(eval, []byte(s.D448.Info))
+		if err != nil {
+			t.Fatal("invalid unblinding of client: " + err.Error())
+		}
+		testOutput, _ := hex.DecodeString(j.Output[2:])
+		if !bytes.Equal(testOutput, h) {
+			test.ReportError(t, h, testOutput, "finalize")
+		}
+	}
+
+	privKey, _ := hex.DecodeString(s.D448Verifiable.PrivK[2:])
+	srv.Kp.PrivK.Set(privKey)
+	for _, j := range s.D448Verifiable.Vector {
+		bToken, _ := hex.DecodeString(j.Blind.Blinded[2:])
+		eval, err := srv.Evaluate(bToken)
+		if err != nil {
+			t.Fatal("invalid evaluation of server: " + err.Error())
+		}
+
+		testEval, _ := hex.DecodeString(j.Evaluation.Eval[2:])
+		if !bytes.Equal(testEval, eval.element) {
+			test.ReportError(t, eval.element, testEval, "eval")
+		}
+	}
+}
+
+// decodeHex decodes a hexadecimal string of the test vectors, which omit
+// leading zeros, into size bytes.
+func decodeHex(t *testing.T, s string, size int) []byte {
+	b, err := hex.DecodeString(strings.Repeat("0", 2*size-len(s)+2) + s[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDraftVectorsVerifiable(t *testing.T) {
+	// Test vectors from draft-05
+	var s Suite
+	s.readFile(t, "testdata/vectors.json")
+
+	for _, v := range []struct {
+		id SuiteID
+		Vectors
+	}{
+		{OPRFDecaf448, s.D448Verifiable},
+		{OPRFP256, s.P256Verifiable},
+		{OPRFP384, s.P384Verifiable},
+		{OPRFP521, s.P521Verifiable},
+	} {
+		suite, err := group.NewSuite(uint16(v.id), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalarSize := len(suite.Order().Serialize())
+		privK := decodeHex(t, v.PrivK, scalarSize)
+		priv := group.NewScalar(suite.Curve).Set(privK)
+		pubK := suite.Generator().ScalarBaseMult(priv).Serialize()
+
+		srv, err := NewVerifiableServerWithKeyPair(v.id, privK, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		client, err := NewVerifiableClient(v.id, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
+		for _, j := range v.Vector {
+			j.Blind.Token = "0x" + hex.EncodeToString(decodeHex(t, j.Blind.Token, scalarSize))
+			cr := blindTest(client.suite, client.ctx, j)
+			cr.pkS = client.pkS
+			testBToken, _ := hex.DecodeString(j.Blind.Blinded[2:])
+			if !bytes.Equal(testBToken, cr.bToken) {
+				test.ReportError(t, cr.bToken, testBToken, v.SuiteName, "request")
+			}
+
+			eval, _, err := srv.EvaluateWithProof(cr.bToken)
+			if err != nil {
+				t.Fatal("invalid evaluation of server: " + err.Error())
+			}
+			testEval, _ := hex.DecodeString(j.Evaluation.Eval[2:])
+			if !bytes.Equal(testEval, eval.element) {
+				test.ReportError(t, eval.element, testEval, v.SuiteName, "eval")
+			}
+
+			proof := &Proof{
+				decodeHex(t, j.Evaluation.Proof.C, scalarSize),
+				decodeHex(t, j.Evaluation.Proof.S, scalarSize),
+			}
+			h, err := cr.FinalizeVerified(eval, proof, []byte(v.Info))
+			if err != nil {
+				t.Fatalf("%v: invalid finalizing of client: %v", v.SuiteName, err)
+			}
+			testOutput, _ := hex.DecodeString(j.Output[2:])
+			if !bytes.Equal(testOutput, h) {
+				test.ReportError(t, h, testOutput, v.SuiteName, "finalize")
+			}
+		}
+	}
+}
+
+func TestEvaluateWithProof(t *testing.T) {
+	in := []byte("test input")
+	info := []byte("test information")
+	for _, id := range []SuiteID{OPRFDecaf448, OPRFP256, OPRFP384, OPRFP521} {
+		srv, err := NewVerifiableServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		pubK, _ := srv.Kp.Serialize()
+		client, err := NewVerifiableClient(id, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
+		cr, err := client.Request(in)
+		if err != nil {
+			t.Fatal("invalid blinding of client: " + err.Error())
+		}
+		eval, proof, err := srv.EvaluateWithProof(cr.bToken)
+		if err != nil {
+			t.Fatal("invalid evaluation of server: " + err.Error())
+		}
+
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof = new(Proof)
+		err = proof.UnmarshalBinary(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h, err := cr.FinalizeVerified(eval, proof, info)
+		if err != nil {
+			t.Fatal("invalid finalizing of client: " + err.Error())
+		}
+		want, err := srv.FullEvaluate(in, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(h, want) {
+			test.ReportError(t, h, want, id)
+		}
+
+		// A proof under another key must fail.
+		other, err := NewVerifiableServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		eval, proof, err = other.EvaluateWithProof(cr.bToken)
+		if err != nil {
+			t.Fatal("invalid evaluation of server: " + err.Error())
+		}
+		_, err = cr.FinalizeVerified(eval, proof, info)
+		if err != ErrInvalidProof {
+			test.ReportError(t, err, ErrInvalidProof, id, "wrong key")
+		}
+
+		// A tampered proof must fail.
+		data[0] ^= 1
+		err = proof.UnmarshalBinary(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eval, _, _ = srv.EvaluateWithProof(cr.bToken)
+		_, err = cr.FinalizeVerified(eval, proof, info)
+		if err != ErrInvalidProof {
+			test.ReportError(t, err, ErrInvalidProof, id, "tampered proof")
+		}
+	}
+
+	srv, _ := NewServer(OPRFP256)
+	client, _ := NewClient(OPRFP256)
+	cr, _ := client.Request(in)
+	if _, _, err := srv.EvaluateWithProof(cr.bToken); err != ErrNotVerifiable {
+		test.ReportError(t, err, ErrNotVerifiable, "server")
+	}
+	eval, _ := srv.Evaluate(cr.bToken)
+	if _, err := cr.FinalizeVerified(eval, &Proof{}, info); err != ErrNotVerifiable {
+		test.ReportError(t, err, ErrNotVerifiable, "client")
+	}
+}
+
+func TestKeyPairAccessors(t *testing.T) {
+	for _, id := range SupportedSuites() {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		kp := srv.Kp
+		wantPub, wantPriv := kp.Serialize()
+		if pub := kp.PublicKey(); !bytes.Equal(pub, wantPub) {
+			test.ReportError(t, pub, wantPub, id)
+		}
+
+		// The returned scalar is a copy, so zeroizing it keeps the key.
+		k := kp.PrivateKeyScalar()
+		if !k.Equal(kp.PrivK) {
+			test.ReportError(t, k.Serialize(), wantPriv, id)
+		}
+		k.Zeroize()
+		if _, priv := kp.Serialize(); !bytes.Equal(priv, wantPriv) {
+			test.ReportError(t, priv, wantPriv, id)
+		}
+		test.CheckNoErr(t, kp.Validate(), "key pair must still be valid")
+	}
+}
+
+func TestServerPublicKey(t *testing.T) {
+	in := []byte("test input")
+	info := []byte("test information")
+	for _, id := range SupportedSuites() {
+		srv, err := NewVerifiableServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		pubK := srv.PublicKey()
+		want, privK := srv.Kp.Serialize()
+		if !bytes.Equal(pubK, want) {
+			test.ReportError(t, pubK, want, id)
+		}
+		guess, err := GuessSuite(pubK)
+		test.CheckNoErr(t, err, "public key must belong to a suite")
+		if guess != id {
+			test.ReportError(t, guess, id)
+		}
+
+		// The returned key is a copy.
+		pubK[0] ^= 1
+		if bytes.Equal(srv.PublicKey(), pubK) {
+			test.ReportError(t, srv.PublicKey(), want, id)
+		}
+		pubK[0] ^= 1
+
+		// A server loaded from the key pair has the same public key.
+		srv2, err := NewVerifiableServerWithKeyPair(id, privK, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		if !bytes.Equal(srv2.PublicKey(), pubK) {
+			test.ReportError(t, srv2.PublicKey(), pubK, id)
+		}
+
+		// A client created with the public key verifies the proofs of the
+		// server.
+		client, err := NewVerifiableClient(id, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+		cr, err := client.Request(in)
+		if err != nil {
+			t.Fatal("invalid blinding of client: " + err.Error())
+		}
+		eval, proof, err := srv2.EvaluateWithProof(cr.bToken)
+		if err != nil {
+			t.Fatal("invalid evaluation of server: " + err.Error())
+		}
+		out, err := cr.FinalizeVerified(eval, proof, info)
+		test.CheckNoErr(t, err, "proof must verify")
+		if !srv.VerifyFinalize(in, info, out) {
+			test.ReportError(t, false, true, id)
+		}
+	}
+}
+
+func TestClientFinalizeDegenerate(t *testing.T) {
+	for _, id := range []SuiteID{OPRFP256, OPRFP384, OPRFP521} {
+		client, err := NewClient(id)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
+		cr, err := client.Request([]byte{00})
+		if err != nil {
+			t.Fatal("invalid blinding of client: " + err.Error())
+		}
+
+		identity := client.suite.Generator().ScalarMult(client.suite.Order())
+		for _, e := range []*Evaluation{
+			{cr.bToken},
+			{identity.Serialize()},
+		} {
+			_, got := cr.Finalize(e, []byte("test information"))
+			want := ErrDegenerateEvaluation
+			if got != want {
+				test.ReportError(t, got, want, id)
+			}
+		}
+	}
+}
+
+func TestKeyPairSerialization(t *testing.T) {
+	for _, id := range []SuiteID{OPRFP256, OPRFP384, OPRFP521} {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+
+		pubK, privK := srv.Kp.Serialize()
+		srv2, err := NewServerWithKeyPair(id, privK, pubK)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+
+		pubK2, privK2 := srv2.Kp.Serialize()
+		if !bytes.Equal(pubK, pubK2) {
+			test.ReportError(t, pubK2, pubK, id)
+		}
+		if !bytes.Equal(privK, privK2) {
+			test.ReportError(t, privK2, privK, id)
+		}
+	}
+}
+
+func TestKeyPairValidate(t *testing.T) {
+	for _, id := range SupportedSuites() {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		other, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		test.CheckNoErr(t, srv.Kp.Validate(), "generated key pair must be valid")
+
+		pubK, privK := srv.Kp.Serialize()
+		otherPubK, otherPrivK := other.Kp.Serialize()
+		_, err = NewServerWithKeyPair(id, privK, pubK)
+		test.CheckNoErr(t, err, "matching key pair must be loaded")
+
+		// Mismatched halves are rejected when loading them.
+		for _, v := range [][2][]byte{
+			{privK, otherPubK},
+			{otherPrivK, pubK},
+		} {
+			_, err = NewServerWithKeyPair(id, v[0], v[1])
+			if err != ErrKeyPairMismatch {
+				test.ReportError(t, err, ErrKeyPairMismatch, id)
+			}
+			_, err = NewVerifiableServerWithKeyPair(id, v[0], v[1])
+			if err != ErrKeyPairMismatch {
+				test.ReportError(t, err, ErrKeyPairMismatch, id)
+			}
+		}
+
+		kp := *srv.Kp
+		kp.PrivK = other.Kp.PrivK
+		if err = kp.Validate(); err != ErrKeyPairMismatch {
+			test.ReportError(t, err, ErrKeyPairMismatch, id)
+		}
+	}
+
+	if err := new(KeyPair).Validate(); err != ErrKeyPairMismatch {
+		test.ReportError(t, err, ErrKeyPairMismatch)
+	}
+}
+
+func TestSupportedSuites(t *testing.T) {
+	for _, id := range SupportedSuites() {
+		for _, mode := range []byte{OPRFMode, VerifiableMode} {
+			ctx := generateContext(mode, id)
+			if got := SuiteID(ctx[1])<<8 | SuiteID(ctx[2]); ctx[0] != mode || got != id {
+				test.ReportError(t, got, id, mode)
+			}
+			suite, err := suiteFromID(id, ctx)
+			test.CheckNoErr(t, err, "suite must be supported")
+			if got := SuiteID(suite.Identifier()); got != id {
+				test.ReportError(t, got, id, mode)
+			}
+		}
+
+		srv, err := NewServer(id)
+		test.CheckNoErr(t, err, "invalid setup of server")
+		_, err = NewClient(id)
+		test.CheckNoErr(t, err, "invalid setup of client")
+		vsrv, err := NewVerifiableServer(id)
+		test.CheckNoErr(t, err, "invalid setup of verifiable server")
+		pubK, _ := vsrv.Kp.Serialize()
+		_, err = NewVerifiableClient(id, pubK)
+		test.CheckNoErr(t, err, "invalid setup of verifiable client")
+		if got := SuiteID(srv.suite.Identifier()); got != id {
+			test.ReportError(t, got, id)
+		}
+	}
+
+	// Both bytes of the ID are encoded.
+	ctx := generateContext(OPRFMode, 0x0102)
+	if !bytes.Equal(ctx, []byte{0x00, 0x01, 0x02}) {
+		test.ReportError(t, ctx, []byte{0x00, 0x01, 0x02})
+	}
+}
+
+func TestGuessSuite(t *testing.T) {
+	for _, id := range SupportedSuites() {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+
+		pubK, _ := srv.Kp.Serialize()
+		got, err := GuessSuite(pubK)
+		if err != nil || got != id {
+			test.ReportError(t, got, id, err)
+		}
+
+		// A public key of the right length that is not canonical.
+		for i := len(pubK) - 1; i > 0; i-- {
+			pubK[i] = 0xff
+		}
+		_, err = GuessSuite(pubK)
+		if err != ErrUnsupportedGroup {
+			test.ReportError(t, err, ErrUnsupportedGroup, id, pubK)
+		}
+	}
+
+	for _, pubK := range [][]byte{nil, make([]byte, 32), {0x02}} {
+		_, err := GuessSuite(pubK)
+		if err != ErrUnsupportedGroup {
+			test.ReportError(t, err, ErrUnsupportedGroup, pubK)
+		}
+	}
+
+	// The identity is serialized as a single zero byte in all NIST suites.
+	_, err := GuessSuite([]byte{0x00})
+	if err != ErrUnsupportedGroup {
+		test.ReportError(t, err, ErrUnsupportedGroup)
+	}
+}
+
+func TestRedeemToken(t *testing.T) {
+	in := []byte("test input")
+	info := []byte("test information")
+	secret := []byte("redemption secret")
+	srv, err := NewServer(OPRFP256)
+	if err != nil {
+		t.Fatal("invalid setup of server: " + err.Error())
+	}
+	client, err := NewClient(OPRFP256)
+	if err != nil {
+		t.Fatal("invalid setup of client: " + err.Error())
+	}
+
+	cr, err := client.Request(in)
+	if err != nil {
+		t.Fatal("invalid blinding of client: " + err.Error())
+	}
+	eval, err := srv.Evaluate(cr.bToken)
+	if err != nil {
+		t.Fatal("invalid evaluation of server: " + err.Error())
+	}
+	tok, err := cr.IssueToken(eval, info)
+	if err != nil {
+		t.Fatal("invalid issuance of token: " + err.Error())
+	}
+	if !bytes.Equal(tok.Input, in) {
+		test.ReportError(t, tok.Input, in)
+	}
+
+	key, err := srv.RedeemToken(tok, info, secret)
+	test.CheckNoErr(t, err, "redemption must succeed")
+	if len(key) != RedemptionKeySize {
+		test.ReportError(t, len(key), RedemptionKeySize)
+	}
+	// The redemption key is deterministic, so a second redemption of the
+	// same token is detected, and it depends on the secret.
+	key2, _ := srv.RedeemToken(tok, info, secret)
+	if !bytes.Equal(key, key2) {
+		test.ReportError(t, key2, key)
+	}
+	key3, _ := srv.RedeemToken(tok, info, []byte("other secret"))
+	if bytes.Equal(key, key3) {
+		test.ReportError(t, key3, key)
+	}
+
+	// A server with another key rejects the token.
+	other, err := NewServer(OPRFP256)
+	if err != nil {
+		t.Fatal("invalid setup of server: " + err.Error())
+	}
+	if _, err := other.RedeemToken(tok, info, secret); err != ErrInvalidToken {
+		test.ReportError(t, err, ErrInvalidToken)
+	}
+	tok.Output[0] ^= 1
+	if _, err := srv.RedeemToken(tok, info, secret); err != ErrInvalidToken {
+		test.ReportError(t, err, ErrInvalidToken)
+	}
+}
+
+func TestVerifyFinalizePrepared(t *testing.T) {
+	in := []byte("test input")
+	info := []byte("test information")
+	for _, id := range SupportedSuites() {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		out, err := srv.FullEvaluate(in, info)
+		if err != nil {
+			t.Fatal("invalid full evaluation of server: " + err.Error())
+		}
+		pi, err := srv.PrepareInput(in)
+		if err != nil {
+			t.Fatal("invalid preparation of input: " + err.Error())
+		}
+
+		// The prepared input is a copy of in.
+		in[0] ^= 1
+		for _, v := range []struct {
+			info, out []byte
+		}{
+			{info, out},
+			{info[1:], out},
+			{info, out[1:]},
+			{info, append([]byte{out[0] ^ 1}, out[1:]...)},
+		} {
+			want := srv.VerifyFinalize([]byte("test input"), v.info, v.out)
+			got := srv.VerifyFinalizePrepared(pi, v.info, v.out)
+			if got != want {
+				test.ReportError(t, got, want, id)
+			}
+		}
+		in[0] ^= 1
+		if !srv.VerifyFinalizePrepared(pi, info, out) {
+			test.ReportError(t, false, true, id)
+		}
+
+		// An input prepared in another mode does not verify.
+		vsrv, err := NewVerifiableServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		vsrv.Kp = srv.Kp
+		vpi, err := vsrv.PrepareInput(in)
+		if err != nil {
+			t.Fatal("invalid preparation of input: " + err.Error())
+		}
+		if srv.VerifyFinalizePrepared(vpi, info, out) {
+			test.ReportError(t, true, false, id)
+		}
+	}
+}
+
+func TestFinalizeBatch(t *testing.T) {
+	info := []byte("test information")
+	for _, id := range []SuiteID{OPRFDecaf448, OPRFP256, OPRFP384, OPRFP521} {
+		srv, err := NewServer(id)
+		if err != nil {
+			t.Fatal("invalid setup of server: " + err.Error())
+		}
+		client, err := NewClient(id)
+		if err != nil {
+			t.Fatal("invalid setup of client: " + err.Error())
+		}
+
+		crs := make([]*ClientRequest, 5)
+		evals := make([]*Evaluation, len(crs))
+		for i := range crs {
+			crs[i], err = client.Request([]byte{byte(i)})
+			test.CheckNoErr(t, err, "invalid blinding of client")
+			evals[i], err = srv.Evaluate(crs[i].bToken)
+			test.CheckNoErr(t, err, "invalid evaluation of server")
+		}
+		outs, err := FinalizeBatch(crs, evals, info)
+		test.CheckNoErr(t, err, "invalid batch finalizing of client")
+		for i := range crs {
+			want, err := crs[i].Finalize(evals[i], info)
+			test.CheckNoErr(t, err, "invalid finalizing of client")
+			if !bytes.Equal(outs[i], want) {
+				test.ReportError(t, outs[i], want, id, i)
+			}
+		}
+
+		_, err = FinalizeBatch(crs, evals[1:], info)
+		test.CheckIsErr(t, err, "mismatched lengths must fail")
+		evals[2] = &Evaluation{element: crs[2].bToken}
+		_, err = FinalizeBatch(crs, evals, info)
+		if err != ErrDegenerateEvaluation {
+			test.ReportError(t, err, ErrDegenerateEvaluation, id)
+		}
+	}
+
+	c1, _ := NewClient(OPRFP256)
+	c2, _ := NewClient(OPRFP384)
+	cr1, _ := c1.Request([]byte("in"))
+	cr2, _ := c2.Request([]byte("in"))
+	_, err := FinalizeBatch([]*ClientRequest{cr1, cr2}, []*Evaluation{{}, {}}, info)
+	test.CheckIsErr(t, err, "mixed suites must fail")
+}
+
+func BenchmarkOPRF(b *testing.B) {
+	in := []byte("test input")
+	info := []byte("test information")
+	for _, v := range []struct {
+		name string
+		id   SuiteID
+	}{
+		{"decaf448", OPRFDecaf448},
+		{"P256", OPRFP256},
+		{"P384", OPRFP384},
+		{"P521", OPRFP521},
+	} {
+		srv, err := NewServer(v.id)
+		if err != nil {
+			b.Fatal("invalid setup of server: " + err.Error())
+		}
+		client, err := NewClient(v.id)
+		if err != nil {
+			b.Fatal("invalid setup of client: " + err.Error())
+		}
+		cr, err := client.Request(in)
+		if err != nil {
+			b.Fatal("invalid blinding of client: " + err.Error())
+		}
+		eval, err := srv.Evaluate(cr.bToken)
+		if err != nil {
+			b.Fatal("invalid evaluation of server: " + err.Error())
+		}
+		out, err := cr.Finalize(eval, info)
+		if err != nil {
+			b.Fatal("invalid finalizing of client: " + err.Error())
+		}
+
+		b.Run(v.name+"/Request", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = client.Request(in)
+			}
+		})
+		b.Run(v.name+"/Evaluate", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = srv.Evaluate(cr.bToken)
+			}
+		})
+		b.Run(v.name+"/Finalize", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = cr.Finalize(eval, info)
+			}
+		})
+		b.Run(v.name+"/FullEvaluate", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = srv.FullEvaluate(in, info)
+			}
+		})
+		b.Run(v.name+"/VerifyFinalize", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = srv.VerifyFinalize(in, info, out)
+			}
+		})
+		crs := make([]*ClientRequest, 64)
+		evals := make([]*Evaluation, len(crs))
+		for i := range crs {
+			crs[i], _ = client.Request(in)
+			evals[i], _ = srv.Evaluate(crs[i].bToken)
+		}
+		b.Run(v.name+"/Finalize64", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range crs {
+					_, _ = crs[j].Finalize(evals[j], info)
+				}
+			}
+		})
+		b.Run(v.name+"/FinalizeBatch64", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = FinalizeBatch(crs, evals, info)
+			}
+		})
+		pi, err := srv.PrepareInput(in)
+		if err != nil {
+			b.Fatal("invalid preparation of input: " + err.Error())
+		}
+		b.Run(v.name+"/VerifyFinalizePrepared", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = srv.VerifyFinalizePrepared(pi, info, out)
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateKeyPair(b *testing.B) {
+	for _, v := range []struct {
+		name string
+		id   SuiteID
+	}{
+		{"decaf448", OPRFDecaf448},
+		{"P256", OPRFP256},
+		{"P384", OPRFP384},
+		{"P521", OPRFP521},
+	} {
+		suite, err := group.NewSuite(uint16(v.id), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(v.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = GenerateKeyPair(suite)
+			}
+		})
+	}
+}
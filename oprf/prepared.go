@@ -0,0 +1,43 @@
+package oprf
+
+import (
+	"bytes"
+
+	"github.com/cloudflare/circl/oprf/group"
+)
+
+// PrecomputedInput is an input of the OPRF protocol already mapped to the
+// group, as returned by PrepareInput. It lets a Server verify the outputs
+// of the same input many times without recomputing HashToGroup, which is
+// the costly part of VerifyFinalize.
+type PrecomputedInput struct {
+	in  []byte
+	ctx []byte
+	p   *group.Element
+}
+
+// PrepareInput maps in to the group as VerifyFinalize does, and returns it
+// to be checked with VerifyFinalizePrepared. The PrecomputedInput only
+// verifies with servers of the same suite and mode as s.
+func (s *Server) PrepareInput(in []byte) (*PrecomputedInput, error) {
+	p, err := s.suite.HashToGroup(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrecomputedInput{
+		in:  append([]byte{}, in...),
+		ctx: s.ctx,
+		p:   p,
+	}, nil
+}
+
+// VerifyFinalizePrepared verifies the evaluation of the prepared input pi,
+// as VerifyFinalize does for its input. It returns false if pi was
+// prepared by a server of another suite or mode.
+func (s *Server) VerifyFinalizePrepared(pi *PrecomputedInput, info, out []byte) bool {
+	if !bytes.Equal(pi.ctx, s.ctx) {
+		return false
+	}
+	return s.verifyFinalize(pi.in, pi.p, info, nil, out)
+}
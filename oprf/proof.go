@@ -0,0 +1,116 @@
+package oprf
+
+import (
+	"errors"
+
+	"github.com/cloudflare/circl/oprf/group"
+)
+
+// Proof is a proof, produced by a Server in the verifiable mode, that an
+// Evaluation uses the private key committed in its public key. It is a
+// non-interactive proof of equality of discrete logarithms (DLEQ) given by
+// the pair of scalars (c, s).
+type Proof struct {
+	c, s []byte
+}
+
+// MarshalBinary returns the serialized scalars c and s of the proof.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(p.c)+len(p.s))
+	out = append(out, p.c...)
+	return append(out, p.s...), nil
+}
+
+// UnmarshalBinary sets the proof to the serialized scalars c and s in data.
+// The scalars are only checked to belong to the group of the suite when the
+// proof is verified.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || len(data)%2 != 0 {
+		return errors.New("invalid proof length")
+	}
+
+	n := len(data) / 2
+	p.c = append([]byte{}, data[:n]...)
+	p.s = append([]byte{}, data[n:]...)
+
+	return nil
+}
+
+// EvaluateWithProof blindly signs a client token as Evaluate does, and
+// proves that the evaluation uses the private key committed in the public
+// key of the Server. It returns ErrNotVerifiable if the Server is not in the
+// verifiable mode.
+func (s *Server) EvaluateWithProof(b BlindToken) (*Evaluation, *Proof, error) {
+	if s.ctx[0] != VerifiableMode {
+		return nil, nil, ErrNotVerifiable
+	}
+
+	p, z, err := s.evaluate(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Proves that log_G(pkS) = log_p(z) = k.
+	g := s.suite.Generator()
+	pi := s.suite.ProveDLEQ(s.Kp.PrivK, g, p, s.Kp.pubK, z, challengeDST(s.ctx))
+
+	return &Evaluation{z.Serialize()}, &Proof{pi.C.Serialize(), pi.S.Serialize()}, nil
+}
+
+// FinalizeVerified is like Finalize, but first verifies the proof that the
+// Evaluation uses the private key committed in the public key of the Server.
+// It returns ErrInvalidProof if the verification fails, and ErrNotVerifiable
+// if the Client is not in the verifiable mode.
+func (cr *ClientRequest) FinalizeVerified(e *Evaluation, p *Proof, info []byte) ([]byte, error) {
+	if cr.pkS == nil {
+		return nil, ErrNotVerifiable
+	}
+
+	err := cr.verifyProof(e, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.Finalize(e, info)
+}
+
+// verifyProof checks that the proof p is valid for the Evaluation e of the
+// blinded token of the request.
+func (cr *ClientRequest) verifyProof(e *Evaluation, p *Proof) error {
+	c := group.NewScalar(cr.suite.Curve)
+	if c.Deserialize(p.c) != nil {
+		return ErrInvalidProof
+	}
+	s := group.NewScalar(cr.suite.Curve)
+	if s.Deserialize(p.s) != nil {
+		return ErrInvalidProof
+	}
+
+	b := group.NewElement(cr.suite.Curve)
+	err := b.Deserialize(cr.bToken)
+	if err != nil {
+		return err
+	}
+	z := group.NewElement(cr.suite.Curve)
+	err = z.Deserialize(e.element)
+	if err != nil {
+		return err
+	}
+	if z.IsIdentity() {
+		return ErrDegenerateEvaluation
+	}
+
+	g := cr.suite.Generator()
+	pi := &group.Proof{C: c, S: s}
+	if !cr.suite.VerifyDLEQ(pi, g, b, cr.pkS, z, challengeDST(cr.ctx)) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// challengeDST returns the domain separation string of the challenge of a
+// proof, as in draft-05.
+func challengeDST(ctx []byte) []byte {
+	return append([]byte(versionPrefix05+"challenge-"), ctx...)
+}
@@ -0,0 +1,53 @@
+package oprf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// RedemptionKeySize is the size, in bytes, of the keys returned by
+// RedeemToken.
+const RedemptionKeySize = sha256.Size
+
+// ErrInvalidToken is an error stating that the output of a RedemptionToken
+// was not computed with the key of the server.
+var ErrInvalidToken = errors.New("the token is invalid")
+
+// RedemptionToken is a token obtained from the OPRF protocol, as in Privacy
+// Pass, which the Client presents to the Server to redeem it.
+type RedemptionToken struct {
+	// Input of the Client to the OPRF protocol.
+	Input []byte
+	// Output of the OPRF protocol.
+	Output []byte
+}
+
+// IssueToken finalizes the evaluation e as Finalize does, and returns the
+// token to present to the Server for redemption.
+func (cr *ClientRequest) IssueToken(e *Evaluation, info []byte) (*RedemptionToken, error) {
+	out, err := cr.Finalize(e, info)
+	if err != nil {
+		return nil, err
+	}
+
+	in := append([]byte{}, cr.token.data...)
+	return &RedemptionToken{Input: in, Output: out}, nil
+}
+
+// RedeemToken checks that the output of tok was computed with the key of
+// the Server, as VerifyFinalize does, and returns its redemption key, that
+// is, HMAC-SHA256 of the output keyed with secret. As tokens are issued
+// without per-client state, the Server detects double spending by keeping
+// the compact redemption keys of redeemed tokens.
+//
+// It returns ErrInvalidToken if the output does not verify.
+func (s *Server) RedeemToken(tok *RedemptionToken, info, secret []byte) ([]byte, error) {
+	if !s.VerifyFinalize(tok.Input, info, tok.Output) {
+		return nil, ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(tok.Output)
+	return mac.Sum(nil), nil
+}
@@ -261,6 +261,19 @@ func (p *Poly) MulHat(a, b *Poly) {
 }
 
 // Puts p into the right form to be used with (among others) InvNTT().
+//
+// The "tangled" order is the order of the coefficients used by the
+// optimized NTT(), InvNTT() and MulHat(), which permutes them to suit the
+// vector instructions.  NTT(), DeriveUniform() and Unpack() output
+// polynomials in tangled order, and InvNTT(), MulHat() and Pack() expect
+// them in tangled order.  Polynomials in natural order, such as those
+// of the test vectors of the specification, must be tangled before being
+// passed to those, and polynomials computed by those must be detangled,
+// see Detangle(), to compare them against the specification.
+//
+// Detangle() undoes Tangle() and vice versa.  Depending on the platform,
+// the tangled order may be the natural order, in which case both do
+// nothing.
 func (p *Poly) Tangle() {
 	if cpu.X86.HasAVX2 {
 		tangleAVX2((*[N]int16)(p))
@@ -269,7 +282,8 @@ func (p *Poly) Tangle() {
 	// When AVX2 is not available, we use the standard order.
 }
 
-// Puts p back into standard form.
+// Puts p back into standard form, that is, the natural order of the
+// coefficients, from the "tangled" order.  See Tangle().
 func (p *Poly) Detangle() {
 	if cpu.X86.HasAVX2 {
 		detangleAVX2((*[N]int16)(p))
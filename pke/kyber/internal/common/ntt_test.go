@@ -140,3 +140,78 @@ func TestInvNTTReductions(t *testing.T) {
 		}
 	}
 }
+
+// Sets p to the product of a and b in ℤ_q[x]/(xᴺ+1), computed by schoolbook
+// multiplication without any of the Montgomery or Barrett arithmetic used by
+// the NTT, to serve as an independent reference.  p will be normalized.
+func (p *Poly) schoolbookMul(a, b *Poly) {
+	var acc [N]int64
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			v := int64(a[i]) * int64(b[j])
+			k := i + j
+			if k >= N {
+				// Recall xᴺ = -1.
+				k -= N
+				v = -v
+			}
+			acc[k] += v
+		}
+	}
+	for i := 0; i < N; i++ {
+		p[i] = int16((acc[i]%int64(Q) + int64(Q)) % int64(Q))
+	}
+}
+
+func TestSchoolbookMul(t *testing.T) {
+	// x²⁵⁵ · (x + 1) = x²⁵⁵ - 1
+	var a, b, p, want Poly
+	a[N-1] = 1
+	b[0], b[1] = 1, 1
+	want[0], want[N-1] = Q-1, 1
+	p.schoolbookMul(&a, &b)
+	if p != want {
+		t.Fatalf("%v\n!= %v", p, want)
+	}
+}
+
+func TestNTTAgainstSchoolbook(t *testing.T) {
+	for k := 0; k < 1000; k++ {
+		var a, b, want Poly
+		a.RandAbsLeQ()
+		b.RandAbsLeQ()
+		want.schoolbookMul(&a, &b)
+
+		// Optimized NTT, which works in tangled order.
+		var p1 Poly
+		ah, bh := a, b
+		ah.NTT()
+		bh.NTT()
+		ah.BarrettReduce()
+		bh.BarrettReduce()
+		p1.MulHat(&ah, &bh)
+		p1.BarrettReduce()
+		p1.InvNTT()
+		p1.Normalize()
+
+		// Generic NTT, which works in regular order.
+		var p2 Poly
+		ah, bh = a, b
+		ah.nttGeneric()
+		bh.nttGeneric()
+		ah.BarrettReduce()
+		bh.BarrettReduce()
+		p2.mulHatGeneric(&ah, &bh)
+		p2.BarrettReduce()
+		p2.invNTTGeneric()
+		p2.Normalize()
+
+		if p1 != want {
+			t.Fatalf("InvNTT(NTT(%v)·NTT(%v)) = \n%v \n!= %v", a, b, p1, want)
+		}
+		if p2 != want {
+			t.Fatalf("generic InvNTT(NTT(%v)·NTT(%v)) = \n%v \n!= %v",
+				a, b, p2, want)
+		}
+	}
+}
@@ -7,6 +7,12 @@ package common
 // see Poly.NTT().
 //
 // Coefficients aren't always reduced.  See Normalize().
+//
+// A polynomial is in Montgomery form if its coefficients are multiplied by
+// the Montgomery factor R=2¹⁶ mod q.  MulHat() divides by R and InvNTT()
+// multiplies by R, so InvNTT(MulHat(NTT(a), NTT(b))) = a b for a and b in
+// regular form, and a b R if one of them is in Montgomery form.  ToMont()
+// and FromMont() convert between both forms.
 type Poly [N]int16
 
 // Sets p to a + b.  Does not normalize coefficients.
@@ -25,7 +31,9 @@ func (p *Poly) subGeneric(a, b *Poly) {
 
 // Almost normalizes coefficients.
 //
-// Ensures each coefficient is in {0, …, q}.
+// Ensures each coefficient is in {0, …, q}.  This suffices to bound the
+// coefficients between arithmetic operations, but not for Pack(),
+// CompressTo() or CompressMessageTo(), which require Normalize().
 func (p *Poly) BarrettReduce() {
 	for i := 0; i < N; i++ {
 		p[i] = barrettReduce(p[i])
@@ -34,7 +42,9 @@ func (p *Poly) BarrettReduce() {
 
 // Normalizes coefficients.
 //
-// Ensures each coefficient is in {0, …, q-1}.
+// Ensures each coefficient is in {0, …, q-1}, for any coefficients,
+// including the negative ones of DeriveNoise().  It is the canonical form
+// required for packing and compression.
 func (p *Poly) Normalize() {
 	for i := 0; i < N; i++ {
 		p[i] = csubq(barrettReduce(p[i]))
@@ -51,6 +61,17 @@ func (p *Poly) ToMont() {
 	}
 }
 
+// Divides p in-place by the Montgomery factor 2¹⁶, that is, takes p out of
+// Montgomery form.
+//
+// Coefficients of p can be arbitrary.  Resulting coefficients are bounded
+// in absolute value by q.
+func (p *Poly) FromMont() {
+	for i := 0; i < N; i++ {
+		p[i] = montReduce(int32(p[i]))
+	}
+}
+
 // Sets p to the "pointwise" multiplication of a and b.
 //
 // That is: InvNTT(p) = InvNTT(a) * InvNTT(b).  Assumes a and b are in
@@ -119,11 +140,13 @@ func (p *Poly) Pack(buf []byte) {
 //
 // buf should be of length PolySize.  p will be "tangled", see Detangle().
 //
-// p will not be normalized; instead 0 ≤ p[i] < 4096.
+// p will be normalized: a 12-bit value x ≥ q in buf is reduced to x - q.
 func (p *Poly) Unpack(buf []byte) {
 	for i := 0; i < 128; i++ {
-		p[2*i] = int16(buf[3*i]) | ((int16(buf[3*i+1]) << 8) & 0xfff)
-		p[2*i+1] = int16(buf[3*i+1]>>4) | (int16(buf[3*i+2]) << 4)
+		// Each 12-bit value is below 4096 < 2q, so a single conditional
+		// subtraction suffices.
+		p[2*i] = csubq(int16(buf[3*i]) | ((int16(buf[3*i+1]) << 8) & 0xfff))
+		p[2*i+1] = csubq(int16(buf[3*i+1]>>4) | (int16(buf[3*i+2]) << 4))
 	}
 	p.Tangle()
 }
@@ -119,6 +119,58 @@ func TestMulHat(t *testing.T) {
 	}
 }
 
+func TestFromMont(t *testing.T) {
+	var p Poly
+	for k := 0; k < 1000; k++ {
+		p.Rand()
+		q := p
+		q.ToMont()
+		q.FromMont()
+		p.Normalize()
+		q.Normalize()
+		if p != q {
+			t.Fatalf("%v\n%v", p, q)
+		}
+	}
+
+	// With a in Montgomery form, the product has to be taken out of it.
+	for k := 0; k < 100; k++ {
+		var a, b, p, ah, bh, ph Poly
+		a.RandAbsLeQ()
+		b.RandAbsLeQ()
+
+		ah = a
+		bh = b
+		ah.ToMont()
+		ah.NTT()
+		bh.NTT()
+		ph.MulHat(&ah, &bh)
+		ph.BarrettReduce()
+		ph.InvNTT()
+		ph.FromMont()
+
+		// Schoolbook multiplication modulo Xᴺ + 1.
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				v := int32(a[i]) * int32(b[j]) % int32(Q)
+				k := i + j
+				if k >= N {
+					k -= N
+					v = -v
+				}
+				p[k] = int16((int32(p[k]) + v) % int32(Q))
+			}
+		}
+
+		p.Normalize()
+		ph.Normalize()
+
+		if p != ph {
+			t.Fatalf("%v\n%v\n%v\n%v", a, b, p, ph)
+		}
+	}
+}
+
 func TestAddAgainstGeneric(t *testing.T) {
 	for k := 0; k < 1000; k++ {
 		var p1, p2, a, b Poly
@@ -204,3 +256,68 @@ func BenchmarkMulHatGeneric(b *testing.B) {
 		p.mulHatGeneric(&p, &p)
 	}
 }
+
+func TestPackUnpack(t *testing.T) {
+	var p, q Poly
+	var buf [PolySize]byte
+	for i := 0; i < 100; i++ {
+		p.Rand()
+		p.Pack(buf[:])
+		q.Unpack(buf[:])
+		if p != q {
+			t.Fatalf("%v != %v", p, q)
+		}
+	}
+}
+
+func TestUnpackReduces(t *testing.T) {
+	var p Poly
+	var buf [PolySize]byte
+	for x := 0; x < 4096; x++ {
+		// Pack x into every coefficient, which is not canonical if x ≥ q.
+		for i := 0; i < 128; i++ {
+			buf[3*i] = byte(x)
+			buf[3*i+1] = byte(x>>8) | byte(x<<4)
+			buf[3*i+2] = byte(x >> 4)
+		}
+		p.Unpack(buf[:])
+		want := int16(x % int(Q))
+		for i := 0; i < N; i++ {
+			if p[i] != want {
+				t.Fatalf("Unpack(%d)[%d] = %d, want %d", x, i, p[i], want)
+			}
+		}
+	}
+}
+
+func TestTangleDetangle(t *testing.T) {
+	for k := 0; k < 100; k++ {
+		var p Poly
+		p.RandAbsLeQ()
+		q := p
+		q.Tangle()
+		q.Detangle()
+		if p != q {
+			t.Fatalf("Detangle(Tangle(%v)) = %v", p, q)
+		}
+		q.Detangle()
+		q.Tangle()
+		if p != q {
+			t.Fatalf("Tangle(Detangle(%v)) = %v", p, q)
+		}
+	}
+
+	// Tangling only permutes the coefficients.
+	var p Poly
+	for i := 0; i < N; i++ {
+		p[i] = int16(i)
+	}
+	p.Tangle()
+	var seen [N]bool
+	for i := 0; i < N; i++ {
+		if p[i] < 0 || int(p[i]) >= N || seen[p[i]] {
+			t.Fatalf("Tangle is not a permutation: %v", p)
+		}
+		seen[p[i]] = true
+	}
+}
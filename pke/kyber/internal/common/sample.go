@@ -2,13 +2,23 @@ package common
 
 import (
 	"github.com/cloudflare/circl/internal/sha3"
+	"github.com/cloudflare/circl/simd/keccakf1600"
 
 	"encoding/binary"
 )
 
+// DeriveX4Available indicates whether the system supports the quick fourway
+// sampling of DeriveNoiseVec.
+var DeriveX4Available = keccakf1600.IsEnabledX4()
+
 // Samples p from a centered binomial distribution with given η.
 //
 // Essentially CBD_η(PRF(seed, nonce)) from the specification.
+//
+// The coefficients of p are signed, in {-η, …, η}, so p has to be brought
+// into {0, …, q-1} with Normalize() before it is packed.  BarrettReduce()
+// is not enough, as it maps multiples of q to q.  The arithmetic, such
+// as NTT(), accepts the signed coefficients as they are.
 func (p *Poly) DeriveNoise(seed []byte, nonce uint8, eta int) {
 	switch eta {
 	case 2:
@@ -22,7 +32,7 @@ func (p *Poly) DeriveNoise(seed []byte, nonce uint8, eta int) {
 
 // Sample p from a centered binomial distribution with n=6 and p=½ - that is:
 // coefficients are in {-3, -2, -1, 0, 1, 2, 3} with probabilities {1/64, 3/32,
-// 15/64, 5/16, 16/64, 3/32, 1/64}.
+// 15/64, 5/16, 15/64, 3/32, 1/64}.
 func (p *Poly) DeriveNoise3(seed []byte, nonce uint8) {
 	keySuffix := [1]byte{nonce}
 	h := sha3.NewShake256()
@@ -37,7 +47,12 @@ func (p *Poly) DeriveNoise3(seed []byte, nonce uint8) {
 	// at the same time (while using only 6.)
 	var buf [192 + 2]byte
 	_, _ = h.Read(buf[:192])
+	p.cbd3(&buf)
+}
 
+// Sets p to the samples of the centered binomial distribution with η=3
+// encoded in the first 192 bytes of buf, as in DeriveNoise3.
+func (p *Poly) cbd3(buf *[192 + 2]byte) {
 	for i := 0; i < 32; i++ {
 		// t is interpreted as a₁ + 2a₂ + 4a₃ + 8b₁ + 16b₂ + ….
 		t := binary.LittleEndian.Uint64(buf[6*i:])
@@ -71,7 +86,12 @@ func (p *Poly) DeriveNoise2(seed []byte, nonce uint8) {
 
 	var buf [128]byte
 	_, _ = h.Read(buf[:])
+	p.cbd2(&buf)
+}
 
+// Sets p to the samples of the centered binomial distribution with η=2
+// encoded in buf, as in DeriveNoise2.
+func (p *Poly) cbd2(buf *[128]byte) {
 	for i := 0; i < 16; i++ {
 		// t is interpreted as a + 2a' + 4b + 8b' + ….
 		t := binary.LittleEndian.Uint64(buf[8*i:])
@@ -89,6 +109,96 @@ func (p *Poly) DeriveNoise2(seed []byte, nonce uint8) {
 	}
 }
 
+// Samples out[i] from a centered binomial distribution with given η, seed
+// and startNonce+i, as DeriveNoise does.
+//
+// Uses the fourway Keccak permutation, if available, to derive up to
+// four polynomials at the same time.
+func DeriveNoiseVec(seed []byte, startNonce uint8, eta int, out []Poly) {
+	if eta != 2 && eta != 3 {
+		panic("unsupported eta")
+	}
+	if !DeriveX4Available || len(seed) != 32 {
+		for i := range out {
+			out[i].DeriveNoise(seed, startNonce+uint8(i), eta)
+		}
+		return
+	}
+
+	for len(out) > 1 {
+		var ps [4]*Poly
+		n := 0
+		for ; n < 4 && n < len(out); n++ {
+			ps[n] = &out[n]
+		}
+		deriveNoiseX4(ps, seed, startNonce, eta)
+		out = out[n:]
+		startNonce += uint8(n)
+	}
+	if len(out) == 1 {
+		out[0].DeriveNoise(seed, startNonce, eta)
+	}
+}
+
+// Samples ps[j] as DeriveNoise does with given η, seed and nonce+j,
+// skipping the nil ones.
+//
+// Can only be called when DeriveX4Available is true and seed is 32 bytes.
+func deriveNoiseX4(ps [4]*Poly, seed []byte, nonce uint8, eta int) {
+	var perm keccakf1600.StateX4
+	state := perm.Initialize()
+
+	// Absorb the seed in the four states
+	for i := 0; i < 4; i++ {
+		v := binary.LittleEndian.Uint64(seed[8*i : 8*(i+1)])
+		for j := 0; j < 4; j++ {
+			state[i*4+j] = v
+		}
+	}
+
+	// Absorb the nonces, the SHAKE256 domain separator (0b1111), the
+	// start of the padding (0b...001) and the end of the padding 0b100...
+	// Recall that the rate of SHAKE256 is 136 --- i.e. 17 uint64s.
+	for j := 0; j < 4; j++ {
+		state[4*4+j] = uint64(nonce+uint8(j)) | (0x1f << 8)
+		state[16*4+j] = 0x80 << 56
+	}
+
+	// η=2 requires 128 bytes, which fit in the rate, and η=3 requires 192
+	// bytes, so the second block of 56 bytes is squeezed as well.
+	var buf [4][192 + 2]byte
+	perm.Permute()
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 4; j++ {
+			binary.LittleEndian.PutUint64(buf[j][8*i:], state[i*4+j])
+		}
+	}
+	if eta == 3 {
+		for j := 0; j < 4; j++ {
+			binary.LittleEndian.PutUint64(buf[j][128:], state[16*4+j])
+		}
+		perm.Permute()
+		for i := 0; i < 7; i++ {
+			for j := 0; j < 4; j++ {
+				binary.LittleEndian.PutUint64(buf[j][136+8*i:], state[i*4+j])
+			}
+		}
+	}
+
+	for j := 0; j < 4; j++ {
+		if ps[j] == nil {
+			continue
+		}
+		if eta == 2 {
+			var buf2 [128]byte
+			copy(buf2[:], buf[j][:128])
+			ps[j].cbd2(&buf2)
+		} else {
+			ps[j].cbd3(&buf[j])
+		}
+	}
+}
+
 // Sample p uniformly from the given seed and x and y coordinates.
 //
 // Coefficients are reduced and will be in "tangled" order.  See Tangle().
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"encoding/binary"
+	"fmt"
 	"testing"
 )
 
@@ -136,3 +138,127 @@ func TestPolyDeriveUniformRef(t *testing.T) {
 		t.Fatalf("%v\n%v", p, want)
 	}
 }
+
+// testDeriveNoiseDistribution checks with a χ² test that the coefficients
+// sampled by DeriveNoise for eta follow CBD_η, whose probabilities are
+// binomial(2η, η+k)/2²ᶯ for k in [-η, η].
+func testDeriveNoiseDistribution(t *testing.T, eta int, critical float64) {
+	const numPolys = 1 << 14
+	counts := make([]int, 2*eta+1)
+	var seed [32]byte
+	var p Poly
+	for i := 0; i < numPolys; i++ {
+		binary.LittleEndian.PutUint32(seed[:], uint32(i))
+		p.DeriveNoise(seed[:], uint8(i), eta)
+		for _, c := range p {
+			if int(c) < -eta || int(c) > eta {
+				t.Fatalf("coefficient %v out of range for eta=%v", c, eta)
+			}
+			counts[int(c)+eta]++
+		}
+	}
+
+	// binomial(2η, j) for j = 0, ..., 2η.
+	binom := []float64{1}
+	for j := 1; j <= 2*eta; j++ {
+		binom = append(binom, binom[j-1]*float64(2*eta-j+1)/float64(j))
+	}
+	total := float64(numPolys * N)
+	chi2 := 0.0
+	for j, c := range counts {
+		expected := total * binom[j] / float64(uint(1)<<uint(2*eta))
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	if chi2 > critical {
+		t.Fatalf("eta=%v: χ²=%v exceeds %v, counts: %v", eta, chi2, critical, counts)
+	}
+}
+
+func TestDeriveNoiseDistribution(t *testing.T) {
+	if !*runVeryLongTest {
+		t.SkipNow()
+	}
+	// Critical values of χ² at significance 0.001 with 2η degrees of
+	// freedom.
+	testDeriveNoiseDistribution(t, 2, 18.467)
+	testDeriveNoiseDistribution(t, 3, 22.458)
+}
+
+func TestDeriveNoiseVec(t *testing.T) {
+	var seed [32]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	for _, eta := range []int{2, 3} {
+		for n := 0; n <= 9; n++ {
+			for _, start := range []uint8{0, 37, 254} {
+				got := make([]Poly, n)
+				DeriveNoiseVec(seed[:], start, eta, got)
+				for i := range got {
+					var want Poly
+					want.DeriveNoise(seed[:], start+uint8(i), eta)
+					if got[i] != want {
+						t.Fatalf("η=%d n=%d start=%d: poly %d differs",
+							eta, n, start, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDeriveNoiseNormalize(t *testing.T) {
+	var seed [32]byte
+	var buf [PolySize]byte
+	for _, eta := range []int{2, 3} {
+		for nonce := 0; nonce < 16; nonce++ {
+			var p Poly
+			seed[0] = byte(nonce)
+			p.DeriveNoise(seed[:], uint8(nonce), eta)
+
+			q := p
+			q.BarrettReduce()
+			r := p
+			r.Normalize()
+			for i := 0; i < N; i++ {
+				if p[i] < int16(-eta) || p[i] > int16(eta) {
+					t.Fatalf("η=%v: noise coefficient %v out of range", eta, p[i])
+				}
+				if q[i] < 0 || q[i] > Q {
+					t.Fatalf("η=%v: reduced coefficient %v out of range", eta, q[i])
+				}
+				if r[i] < 0 || r[i] >= Q || (int(r[i])-int(p[i]))%int(Q) != 0 {
+					t.Fatalf("η=%v: %v normalizes to %v", eta, p[i], r[i])
+				}
+			}
+
+			// Normalized noise survives packing.
+			var r2 Poly
+			r.Pack(buf[:])
+			r2.Unpack(buf[:])
+			if r != r2 {
+				t.Fatalf("η=%v: packing normalized noise fails", eta)
+			}
+		}
+	}
+}
+
+func BenchmarkDeriveNoiseVec(b *testing.B) {
+	var seed [32]byte
+	for _, eta := range []int{2, 3} {
+		ps := make([]Poly, 4)
+		b.Run(fmt.Sprintf("eta=%d/DeriveNoise", eta), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range ps {
+					ps[j].DeriveNoise(seed[:], uint8(j), eta)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("eta=%d/DeriveNoiseVec", eta), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				DeriveNoiseVec(seed[:], 0, eta, ps)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"bytes"
+
 	"github.com/cloudflare/circl/internal/sha3"
 	"github.com/cloudflare/circl/pke/kyber/internal/common"
 )
@@ -37,11 +39,20 @@ func (pk *PublicKey) Pack(buf []byte) {
 }
 
 // Unpacks the public key from buf.
-func (pk *PublicKey) Unpack(buf []byte) {
+//
+// Returns whether the coefficients of t̂ in buf are canonical, that is,
+// smaller than q, as required by the modulus check of ML-KEM.  Otherwise,
+// they are reduced modulo q.
+func (pk *PublicKey) Unpack(buf []byte) bool {
 	pk.th.Unpack(buf)
-	pk.th.Normalize()
 	copy(pk.rho[:], buf[K*common.PolySize:])
 	pk.aT.Derive(&pk.rho, true)
+
+	// As Unpack() reduces the coefficients, buf is canonical if and only
+	// if packing t̂ again gives back buf.
+	var buf2 [K * common.PolySize]byte
+	pk.th.Pack(buf2[:])
+	return bytes.Equal(buf[:len(buf2)], buf2[:])
 }
 
 // Derives a new Kyber.CPAPKE keypair from the given seed.
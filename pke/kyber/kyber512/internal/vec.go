@@ -12,9 +12,7 @@ type Vec [K]common.Poly
 //
 // Essentially CBD_η(PRF(seed, nonce+i)) from the specification.
 func (v *Vec) DeriveNoise(seed []byte, nonce uint8, eta int) {
-	for i := 0; i < K; i++ {
-		v[i].DeriveNoise(seed, nonce+uint8(i), eta)
-	}
+	common.DeriveNoiseVec(seed, nonce, eta, v[:])
 }
 
 // Sets p to the inner product of a and b using "pointwise" multiplication.
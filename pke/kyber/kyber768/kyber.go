@@ -9,6 +9,7 @@ package kyber768
 
 import (
 	cryptoRand "crypto/rand"
+	"errors"
 	"io"
 
 	"github.com/cloudflare/circl/pke/kyber/kyber768/internal"
@@ -121,12 +122,16 @@ func (sk *PrivateKey) Pack(buf []byte) {
 
 // Unpacks pk from the given buffer.
 //
-// Panics if buf is not of length PublicKeySize.
-func (pk *PublicKey) Unpack(buf []byte) {
+// Returns an error if the coefficients of pk are not canonical, that is,
+// smaller than q.  Panics if buf is not of length PublicKeySize.
+func (pk *PublicKey) Unpack(buf []byte) error {
 	if len(buf) != PublicKeySize {
 		panic("buf must be of size PublicKeySize")
 	}
-	(*internal.PublicKey)(pk).Unpack(buf)
+	if !(*internal.PublicKey)(pk).Unpack(buf) {
+		return errors.New("public key is not normalized")
+	}
+	return nil
 }
 
 // Unpacks sk from the given buffer.
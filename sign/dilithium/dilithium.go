@@ -24,11 +24,17 @@
 // implement such hybrids of Dilithium3 with Ed25519 respectively and
 // Dilithium4 with Ed448.  These packages are a drop in replacements for the
 // mode subpackages of this package.
+//
+// When built with the selftest tag, each mode signs a fixed message at
+// initialization and panics if the signature does not match the stored
+// known answer.
 package dilithium
 
 import (
 	"crypto"
 	"io"
+
+	"github.com/cloudflare/circl/internal/sha3"
 )
 
 // PublicKey is a Dilithium public key.
@@ -116,3 +122,15 @@ func ModeNames() []string {
 func ModeByName(name string) Mode {
 	return modes[name]
 }
+
+// Fingerprint returns the SHAKE-256 digest of the packed public key, which
+// is a short identifier for it, for instance for logging or key pinning.
+// As it is computed over the canonical packed form, it is stable across
+// processes.
+func Fingerprint(pk PublicKey) [32]byte {
+	var fp [32]byte
+	h := sha3.NewShake256()
+	_, _ = h.Write(pk.Bytes())
+	_, _ = h.Read(fp[:])
+	return fp
+}
@@ -1,6 +1,7 @@
 package dilithium
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
@@ -54,3 +55,29 @@ func TestNewKeyFromSeed(t *testing.T) {
 	testNewKeyFromSeed(t, "Dilithium4-AES",
 		"7c1c8b5df63fd096901da43c00fa71e8", "f7f850c1d8ff82c868ab2f188ac624b3")
 }
+
+func TestFingerprint(t *testing.T) {
+	for _, name := range ModeNames() {
+		mode := ModeByName(name)
+		pk, _, err := mode.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk2, _, err := mode.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fp := Fingerprint(pk)
+		parsed := mode.PublicKeyFromBytes(pk.Bytes())
+		if !bytes.Equal(parsed.Bytes(), pk.Bytes()) {
+			t.Fatalf("%s: re-marshaled public key differs", name)
+		}
+		if Fingerprint(parsed) != fp {
+			t.Fatalf("%s: fingerprint of parsed public key differs", name)
+		}
+		if Fingerprint(pk2) == fp {
+			t.Fatalf("%s: different public keys have the same fingerprint", name)
+		}
+	}
+}
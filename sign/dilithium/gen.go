@@ -1,3 +1,4 @@
+//go:build ignore
 // +build ignore
 
 // Autogenerates wrappers from templates to prevent too much duplicated code
@@ -26,6 +27,7 @@ type Mode struct {
 	DoubleEtaBits  int
 	Beta           int
 	Omega          int
+	SelfTestDigest string
 }
 
 func (m Mode) Pkg() string {
@@ -53,6 +55,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           375,
 			Omega:          64,
+			SelfTestDigest: "df3cf0606f289ebc33312bea049a20a62fa30bf1e7731f0b281cd949dcb13358",
 		},
 		{
 			Name:           "Dilithium1-AES",
@@ -66,6 +69,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           375,
 			Omega:          64,
+			SelfTestDigest: "3ceeb9638cfec15eb6c1247eda8a217dabb1d06fcb5f24b986aaff6a6ed781ac",
 		},
 		{
 			Name:           "Dilithium2",
@@ -79,6 +83,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           325,
 			Omega:          80,
+			SelfTestDigest: "7e56b0b283164481e6517c7dfc19b59da04ba85867530ebbbf2239bc8654957c",
 		},
 		{
 			Name:           "Dilithium2-AES",
@@ -92,6 +97,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           325,
 			Omega:          80,
+			SelfTestDigest: "4f8472900971cba5b5b027e36cf7970ac9f7132195d60ab9a4399d22db4c19a4",
 		},
 		{
 			Name:           "Dilithium3",
@@ -105,6 +111,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           275,
 			Omega:          96,
+			SelfTestDigest: "5fcc0312e1443c6982f5a99791555d582719481c175760c84eb8b52cffe0e9f0",
 		},
 		{
 			Name:           "Dilithium3-AES",
@@ -118,6 +125,7 @@ var (
 			DoubleEtaBits:  4,
 			Beta:           275,
 			Omega:          96,
+			SelfTestDigest: "eeda2fa9b7d5589a1210cf2becc62eae4713bf7267bb3c93d8e3ea1a1126673c",
 		}, {
 			Name:           "Dilithium4",
 			UseAES:         false,
@@ -130,6 +138,7 @@ var (
 			DoubleEtaBits:  3,
 			Beta:           175,
 			Omega:          120,
+			SelfTestDigest: "1740c9933d6d33f74f466ba2199c6b0f90b6a5139ee3390e4f621096fbc0a924",
 		}, {
 			Name:           "Dilithium4-AES",
 			UseAES:         true,
@@ -142,6 +151,7 @@ var (
 			DoubleEtaBits:  3,
 			Beta:           175,
 			Omega:          120,
+			SelfTestDigest: "b3269898c3f5e128bf9d13b4a52917b1987a95bbe4086aa24f5d913ae8060ab0",
 		},
 	}
 	TemplateWarning = "// Code generated from"
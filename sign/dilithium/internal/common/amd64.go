@@ -54,6 +54,10 @@ func (p *Poly) MulHat(a, b *Poly) {
 }
 
 // Sets p to a + b.  Does not normalize polynomials.
+//
+// If the coefficients of a and b are less than 2q, then those of p are
+// less than 4q.  Use ReduceLe2Q() to bring them back below 2q, so that
+// additions and subtractions can be chained and normalized at the end.
 func (p *Poly) Add(a, b *Poly) {
 	if cpu.X86.HasAVX2 {
 		addAVX2(
@@ -66,10 +70,11 @@ func (p *Poly) Add(a, b *Poly) {
 	}
 }
 
-// Sets p to a - b.
+// Sets p to a - b.  Does not normalize polynomials.
 //
-// Warning: assumes coefficients of b are less than 2q.
-// Sets p to a + b.  Does not normalize polynomials.
+// Warning: assumes coefficients of b are less than 2q.  If the coefficients
+// of a are also less than 2q, then those of p are less than 4q.  Use
+// ReduceLe2Q() to bring them back below 2q.
 func (p *Poly) Sub(a, b *Poly) {
 	if cpu.X86.HasAVX2 {
 		subAVX2(
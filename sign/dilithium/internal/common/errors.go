@@ -0,0 +1,24 @@
+package common
+
+import "errors"
+
+// Errors returned by VerifyWithError on the causes of a failed
+// verification.  They are shared by all modes.
+var (
+	// ErrBadSignatureLength is returned for signatures that are not of
+	// the size of the mode.
+	ErrBadSignatureLength = errors.New("dilithium: bad signature length")
+
+	// ErrZOutOfRange is returned for signatures whose z is not packed
+	// properly, or has a coefficient of norm at least γ₁ - β.
+	ErrZOutOfRange = errors.New("dilithium: z out of range")
+
+	// ErrHintInvalid is returned for signatures whose hint is not
+	// canonically packed, or has more than ω non-zero coefficients.
+	ErrHintInvalid = errors.New("dilithium: invalid hint")
+
+	// ErrChallengeMismatch is returned for well-formed signatures whose
+	// challenge differs from the one recomputed from the message and
+	// public key.
+	ErrChallengeMismatch = errors.New("dilithium: challenge mismatch")
+)
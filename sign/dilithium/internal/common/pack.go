@@ -179,6 +179,15 @@ func (p *Poly) UnpackLeGamma1(buf []byte) {
 	}
 }
 
+// Sets p to the polynomial packed into buf by PackLeGamma1 as
+// UnpackLeGamma1 does, and returns whether the coefficients of p are all
+// less than γ₁ in norm, that is, whether buf could have been written by
+// PackLeGamma1.
+func (p *Poly) UnpackLeGamma1Checked(buf []byte) bool {
+	p.UnpackLeGamma1(buf)
+	return !p.Exceeds(Gamma1)
+}
+
 // Writes p whose coefficients are in [0, 16) to buf, which must be of
 // length N/2.
 func (p *Poly) packLe16Generic(buf []byte) {
@@ -43,3 +43,54 @@ func BenchmarkUnpackLeGamma1(b *testing.B) {
 		p.UnpackLeGamma1(buf[:])
 	}
 }
+
+// Packs the 20-bit values vs into buf as PackLeGamma1 does with the
+// coefficients γ₁-1-vs[i], without checking their range.
+func packRawLeGamma1(vs *[N]uint32, buf []byte) {
+	j := 0
+	for i := 0; i < PolyLeGamma1Size; i += 5 {
+		buf[i] = byte(vs[j])
+		buf[i+1] = byte(vs[j] >> 8)
+		buf[i+2] = byte(vs[j]>>16) | byte(vs[j+1]<<4)
+		buf[i+3] = byte(vs[j+1] >> 4)
+		buf[i+4] = byte(vs[j+1] >> 12)
+		j += 2
+	}
+}
+
+func TestUnpackLeGamma1Checked(t *testing.T) {
+	var p, q Poly
+	var vs [N]uint32
+	var buf [PolyLeGamma1Size]byte
+
+	// Goes over all the 2²⁰ packed values, of which only those up to
+	// 2(γ₁-1) are coefficients less than γ₁ in norm.
+	for base := uint32(0); base < 1<<20; base += N {
+		inRange := true
+		for i := 0; i < N; i++ {
+			vs[i] = base + uint32(i)
+			inRange = inRange && vs[i] <= 2*(Gamma1-1)
+		}
+		packRawLeGamma1(&vs, buf[:])
+		if p.UnpackLeGamma1Checked(buf[:]) != inRange {
+			t.Fatalf("UnpackLeGamma1Checked() != %v at %d", inRange, base)
+		}
+		for i := 0; i < N; i++ {
+			want := (Q + Gamma1 - 1 - vs[i]) % Q
+			if p[i] != want {
+				t.Fatalf("%d != %d", p[i], want)
+			}
+		}
+	}
+
+	// In-range polynomials round-trip.
+	for j := 0; j < 1000; j++ {
+		for i := 0; i < N; i++ {
+			p[i] = (Q + Gamma1 - 1 - uint32(rand.Intn(2*Gamma1-1))) % Q
+		}
+		p.PackLeGamma1(buf[:])
+		if !q.UnpackLeGamma1Checked(buf[:]) || p != q {
+			t.Fatalf("%v != %v", p, q)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 const (
 	SeedSize         = params.SeedSize
+	RndSize          = params.RndSize
 	N                = params.N
 	Q                = params.Q
 	QBits            = params.QBits
@@ -5,6 +5,7 @@ package params
 
 const (
 	SeedSize = 32
+	RndSize  = 32 // Size of the randomness of SignToWithRnd.
 	N        = 256
 	Q        = 8380417 // 2²³ - 2¹³ + 1
 	QBits    = 23
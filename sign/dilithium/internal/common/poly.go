@@ -70,6 +70,29 @@ func (p *Poly) exceedsGeneric(bound uint32) bool {
 	return false
 }
 
+// Returns the "supnorm" (see sec 2.1 of the spec) of p, that is, the
+// largest norm of the central representatives of its coefficients.
+//
+// Unlike Exceeds, it always reads all the coefficients and does not branch
+// on them, so that it does not leak which coefficient is the largest.
+//
+// Requires the coefficients of p to be normalized.
+func (p *Poly) Norm() uint32 {
+	var m uint32
+	for i := 0; i < N; i++ {
+		// Computes the norm x of p[i] as exceedsGeneric() does.
+		x := int32((Q-1)/2) - int32(p[i])
+		x ^= (x >> 31)
+		x = int32((Q-1)/2) - x
+
+		// Sets m to max(m, x).  Both are less than 2³¹, so m - x is
+		// negative if and only if x > m.
+		mask := uint32((int32(m) - x) >> 31)
+		m ^= (m ^ uint32(x)) & mask
+	}
+	return m
+}
+
 // Splits each of the coefficients using decompose.
 //
 // Requires p to be normalized.
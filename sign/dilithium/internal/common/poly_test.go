@@ -1,6 +1,7 @@
 package common
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 )
@@ -100,6 +101,49 @@ func TestAddAgainstGeneric(t *testing.T) {
 	}
 }
 
+func TestAddSubAgainstBigInt(t *testing.T) {
+	q := big.NewInt(Q)
+	for k := 0; k < 100; k++ {
+		var a, b, c, p Poly
+		a.RandLe2Q()
+		b.RandLe2Q()
+		c.RandLe2Q()
+
+		// p = (a + b) - c, reducing in between and normalizing at the end.
+		p.Add(&a, &b)
+		for j := 0; j < N; j++ {
+			if p[j] >= 4*Q {
+				t.Fatalf("Add() coefficient %v is not below 4q", p[j])
+			}
+		}
+		p.ReduceLe2Q()
+		p.Sub(&p, &c)
+		for j := 0; j < N; j++ {
+			if p[j] >= 4*Q {
+				t.Fatalf("Sub() coefficient %v is not below 4q", p[j])
+			}
+		}
+		p.ReduceLe2Q()
+		for j := 0; j < N; j++ {
+			if p[j] >= 2*Q {
+				t.Fatalf("ReduceLe2Q() coefficient %v is not below 2q", p[j])
+			}
+		}
+		p.Normalize()
+
+		for j := 0; j < N; j++ {
+			want := big.NewInt(int64(a[j]))
+			want.Add(want, big.NewInt(int64(b[j])))
+			want.Sub(want, big.NewInt(int64(c[j])))
+			want.Mod(want, q)
+			if uint64(p[j]) != want.Uint64() {
+				t.Fatalf("(%v + %v) - %v = %v != %v (mod q)",
+					a[j], b[j], c[j], p[j], want)
+			}
+		}
+	}
+}
+
 func TestMulHatAgainstGeneric(t *testing.T) {
 	for k := 0; k < 1000; k++ {
 		var p1, p2, a, b Poly
@@ -296,3 +340,44 @@ func BenchmarkMulBy2toD(b *testing.B) {
 		p.MulBy2toD(&q)
 	}
 }
+
+func TestNorm(t *testing.T) {
+	for k := 0; k < 1000; k++ {
+		var p Poly
+		for i := 0; i < N; i++ {
+			if k%2 == 0 {
+				p[i] = rand.Uint32() % Q // nolint:gosec
+			} else {
+				// Small coefficients, as in the bound checks of signing.
+				x := rand.Uint32() % (2 * Gamma2) // nolint:gosec
+				p[i] = (Q + x - Gamma2) % Q
+			}
+		}
+
+		var want uint32
+		for i := 0; i < N; i++ {
+			x := p[i]
+			if x > (Q-1)/2 {
+				x = Q - x
+			}
+			if x > want {
+				want = x
+			}
+		}
+		if got := p.Norm(); got != want {
+			t.Fatalf("Norm(%v) = %d != %d", p, got, want)
+		}
+		if !p.Exceeds(want) || p.Exceeds(want+1) {
+			t.Fatal("Norm does not agree with Exceeds")
+		}
+	}
+
+	var p Poly
+	if p.Norm() != 0 {
+		t.Fatal()
+	}
+	p[N-1] = (Q + 1) / 2
+	if p.Norm() != (Q-1)/2 {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,71 @@
+package dilithium
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// KeyTypeAKP is the JWK key type of the public keys marshaled by MarshalJWK,
+// namely the "Algorithm Key Pair" type proposed for post-quantum signature
+// schemes, whose keys are raw byte strings bound to a single algorithm.
+const KeyTypeAKP = "AKP"
+
+// ErrJWK is returned by UnmarshalJWK for JWKs that are not Dilithium
+// public keys of a supported mode.
+var ErrJWK = errors.New("dilithium: invalid JWK")
+
+// jwk holds the members of a JWK used for Dilithium public keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Pub string `json:"pub"`
+}
+
+// MarshalJWK returns the JSON Web Key (RFC 7517) of the public key pk of
+// the given mode, that is,
+//
+//   {"kty":"AKP","alg":<name of the mode>,"pub":<base64url of pk>},
+//
+// where pk is packed as by its Bytes method and encoded without padding.
+//
+// The modes of this package implement round 2 Dilithium, which is not
+// ML-DSA, so the "ML-DSA-44", "ML-DSA-65" and "ML-DSA-87" identifiers
+// assigned for JOSE and COSE do not apply to them. The name of the mode,
+// such as "Dilithium3", is used as the algorithm instead, so that these
+// keys are never mistaken for ML-DSA keys.
+func MarshalJWK(mode Mode, pk PublicKey) ([]byte, error) {
+	b := pk.Bytes()
+	if len(b) != mode.PublicKeySize() {
+		return nil, errors.New("dilithium: public key of another mode")
+	}
+	return json.Marshal(jwk{
+		Kty: KeyTypeAKP,
+		Alg: mode.Name(),
+		Pub: base64.RawURLEncoding.EncodeToString(b),
+	})
+}
+
+// UnmarshalJWK returns the public key encoded in the JWK data by
+// MarshalJWK, and its mode. It returns ErrJWK if the key type is not AKP,
+// if the algorithm is not the name of a mode, or if the public key is not
+// of the size of the mode. Other members of the JWK, such as "kid", are
+// ignored.
+func UnmarshalJWK(data []byte) (Mode, PublicKey, error) {
+	var k jwk
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, nil, err
+	}
+	if k.Kty != KeyTypeAKP {
+		return nil, nil, ErrJWK
+	}
+	mode := ModeByName(k.Alg)
+	if mode == nil {
+		return nil, nil, ErrJWK
+	}
+	pub, err := base64.RawURLEncoding.DecodeString(k.Pub)
+	if err != nil || len(pub) != mode.PublicKeySize() {
+		return nil, nil, ErrJWK
+	}
+	return mode, mode.PublicKeyFromBytes(pub), nil
+}
@@ -0,0 +1,69 @@
+package dilithium
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestJWK(t *testing.T) {
+	mode := ModeByName("Dilithium2")
+	pk, _ := mode.NewKeyFromSeed(make([]byte, mode.SeedSize()))
+	pub := base64.RawURLEncoding.EncodeToString(pk.Bytes())
+
+	// Hand-constructed JWK of pk.
+	want := `{"kty":"AKP","alg":"Dilithium2","pub":"` + pub + `"}`
+	got, err := MarshalJWK(mode, pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("MarshalJWK() = %s, want %s", got, want)
+	}
+
+	mode2, pk2, err := UnmarshalJWK([]byte(`{"kid":"1","pub":"` + pub +
+		`","alg":"Dilithium2","kty":"AKP"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode2 != mode || !bytes.Equal(pk2.Bytes(), pk.Bytes()) {
+		t.Fatal("round trip of JWK failed")
+	}
+
+	for _, name := range ModeNames() {
+		mode := ModeByName(name)
+		pk, _, _ := mode.GenerateKey(nil)
+		data, err := MarshalJWK(mode, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mode2, pk2, err := UnmarshalJWK(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode2 != mode || !bytes.Equal(pk2.Bytes(), pk.Bytes()) {
+			t.Fatalf("%v: round trip of JWK failed", name)
+		}
+	}
+
+	if _, err := MarshalJWK(ModeByName("Dilithium3"), pk); err == nil {
+		t.Fatal("public key of another mode must be rejected")
+	}
+
+	short := base64.RawURLEncoding.EncodeToString(pk.Bytes()[1:])
+	for _, data := range []string{
+		`{"kty":"OKP","alg":"Dilithium2","pub":"` + pub + `"}`,
+		`{"kty":"AKP","alg":"ML-DSA-44","pub":"` + pub + `"}`,
+		`{"kty":"AKP","alg":"Dilithium3","pub":"` + pub + `"}`,
+		`{"kty":"AKP","alg":"Dilithium2","pub":"` + short + `"}`,
+		`{"kty":"AKP","alg":"Dilithium2","pub":"` + pub + `="}`,
+		`{"kty":"AKP","alg":"Dilithium2"}`,
+	} {
+		if _, _, err := UnmarshalJWK([]byte(data)); err != ErrJWK {
+			t.Fatalf("UnmarshalJWK(%.60s…) = %v, want ErrJWK", data, err)
+		}
+	}
+	if _, _, err := UnmarshalJWK([]byte(`{"kty":`)); err == nil {
+		t.Fatal("malformed JSON must be rejected")
+	}
+}
@@ -1,16 +1,27 @@
 package dilithium
 
-// Code to generate the NIST "PQCsignKAT" test vectors.
+// Code to generate and check the NIST "PQCsignKAT" test vectors.
 // See PQCsignKAT_sign.c and randombytes.c in the reference implementation.
 
 import (
+	"archive/zip"
+	"bytes"
 	"crypto/sha256"
+	"flag"
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/cloudflare/circl/internal/nist"
+	"github.com/cloudflare/circl/internal/test"
 )
 
+// Indicates whether long tests should be run
+var runLongTest = flag.Bool("long", false, "runs longer tests")
+
+// Number of vectors of each mode checked unless the -long flag is given.
+const katShortCount = 10
+
 func TestPQCgenKATSign(t *testing.T) {
 	// From SHA256SUMS in the reference implementation.
 	testPQCgenKATSign(t, "Dilithium1", "dd83f8584fded0398547827edff081969335c32069f3e4a9dbd865fd5c2ecd2b")
@@ -58,3 +69,166 @@ func testPQCgenKATSign(t *testing.T, name, expected string) {
 		t.Fatal()
 	}
 }
+
+// katVector is an entry of a PQCsignKAT_*.req or PQCsignKAT_*.rsp file.
+// In request files, only count, seed, mlen, msg, and the optional ctxlen
+// and ctx are set.
+type katVector struct {
+	Line   int    `kat:",line"`
+	Count  int    `kat:"count"`
+	Seed   []byte `kat:"seed"`
+	Mlen   int    `kat:"mlen"`
+	Msg    []byte `kat:"msg"`
+	Ctxlen int    `kat:"ctxlen"`
+	Ctx    []byte `kat:"ctx"`
+	Pk     []byte `kat:"pk"`
+	Sk     []byte `kat:"sk"`
+	Smlen  int    `kat:"smlen"`
+	Sm     []byte `kat:"sm"`
+}
+
+// readKAT parses the vectors in the KAT file f.
+func readKAT(f *zip.File) ([]katVector, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var vs []katVector
+	err = test.ReadKAT(r, f.Name, &vs)
+	return vs, err
+}
+
+func TestPQCsignKAT(t *testing.T) {
+	const nameFile = "testdata/PQCsignKAT.zip"
+	zipFile, err := zip.OpenReader(nameFile)
+	if err != nil {
+		t.Fatalf("File %v can not be opened. Error: %v", nameFile, err)
+	}
+	defer zipFile.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zipFile.File {
+		files[f.Name] = f
+	}
+
+	names := ModeNames()
+	sort.Strings(names)
+	for _, name := range names {
+		mode := ModeByName(name)
+		t.Run(name, func(t *testing.T) {
+			req, rsp := files["PQCsignKAT_"+name+".req"], files["PQCsignKAT_"+name+".rsp"]
+			if req == nil || rsp == nil {
+				t.Fatalf("Missing KAT files of %v", name)
+			}
+			reqs, err := readKAT(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rsps, err := readKAT(rsp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reqs) != len(rsps) {
+				t.Fatalf("%v requests but %v responses", len(reqs), len(rsps))
+			}
+
+			n := len(rsps)
+			if !*runLongTest && n > katShortCount {
+				t.Logf("Skipped %v vectors, add -long flag to run longer tests", n-katShortCount)
+				n = katShortCount
+			}
+			for i := 0; i < n; i++ {
+				if len(rsps[i].Ctx) != 0 {
+					// The modes implement round 2 Dilithium, whose signatures
+					// are not bound to a context as those of ML-DSA are.
+					t.Run(fmt.Sprintf("count=%v", rsps[i].Count), func(t *testing.T) {
+						t.Skipf("line %v: %v does not support signing with a context", rsps[i].Line, name)
+					})
+					continue
+				}
+				testPQCsignKATVector(t, mode, &reqs[i], &rsps[i])
+			}
+		})
+	}
+}
+
+func testPQCsignKATVector(t *testing.T, mode Mode, req, rsp *katVector) {
+	sigSize := mode.SignatureSize()
+	if req.Count != rsp.Count || req.Mlen != rsp.Mlen ||
+		!bytes.Equal(req.Seed, rsp.Seed) || !bytes.Equal(req.Msg, rsp.Msg) ||
+		req.Ctxlen != rsp.Ctxlen || !bytes.Equal(req.Ctx, rsp.Ctx) {
+		t.Fatalf("line %v: request does not match response", req.Line)
+	}
+	if len(rsp.Ctx) != rsp.Ctxlen {
+		t.Fatalf("line %v: malformed context", rsp.Line)
+	}
+	if len(rsp.Seed) != 48 || len(rsp.Msg) != rsp.Mlen ||
+		len(rsp.Sm) != rsp.Smlen || rsp.Smlen != rsp.Mlen+sigSize ||
+		!bytes.Equal(rsp.Sm[sigSize:], rsp.Msg) {
+		t.Fatalf("line %v: malformed response", rsp.Line)
+	}
+	sig := rsp.Sm[:sigSize]
+
+	// The reference implementation generates the key pair from the
+	// randombytes() DRBG seeded with the seed of the vector.
+	var seed [48]byte
+	var eseed [96]byte
+	copy(seed[:], rsp.Seed)
+	g := nist.NewDRBG(&seed)
+	g.Fill(eseed[:])
+	pk, sk := mode.NewKeyFromExpandedSeed(&eseed)
+	if !bytes.Equal(pk.Bytes(), rsp.Pk) {
+		t.Errorf("line %v: public key does not match", rsp.Line)
+	}
+	if !bytes.Equal(sk.Bytes(), rsp.Sk) {
+		t.Errorf("line %v: private key does not match", rsp.Line)
+	}
+	if !bytes.Equal(mode.Sign(sk, rsp.Msg), sig) {
+		t.Errorf("line %v: signature does not match", rsp.Line)
+	}
+
+	// Check the keys decoded from the vector as well.
+	pk2 := mode.PublicKeyFromBytes(rsp.Pk)
+	sk2 := mode.PrivateKeyFromBytes(rsp.Sk)
+	if !bytes.Equal(mode.Sign(sk2, rsp.Msg), sig) {
+		t.Errorf("line %v: signature with decoded key does not match", rsp.Line)
+	}
+	if !mode.Verify(pk2, rsp.Msg, sig) {
+		t.Errorf("line %v: signature does not verify", rsp.Line)
+	}
+	msg := append([]byte{}, rsp.Msg...)
+	msg[0] ^= 1
+	if mode.Verify(pk2, msg, sig) {
+		t.Errorf("line %v: signature verifies a different message", rsp.Line)
+	}
+}
+
+func TestReadKATContext(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("PQCsignKAT_test.req")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(w, "# test\n\ncount = 0\nseed = 00\nmlen = 1\nmsg = AB\n"+
+		"ctxlen = 0\nctx =\n\ncount = 1\nseed = 01\nmlen = 1\nmsg = CD\n"+
+		"ctxlen = 2\nctx = 0102\n")
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, err := readKAT(zr.File[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 2 || vs[0].Ctxlen != 0 || len(vs[0].Ctx) != 0 ||
+		vs[1].Ctxlen != 2 || !bytes.Equal(vs[1].Ctx, []byte{1, 2}) {
+		t.Fatalf("context not parsed: %+v", vs)
+	}
+}
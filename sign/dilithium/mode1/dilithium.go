@@ -19,6 +19,9 @@ const (
 	// Size of seed for NewKeyFromSeed
 	SeedSize = common.SeedSize
 
+	// Size of the randomness for SignToWithRnd
+	RndSize = common.RndSize
+
 	// Size of a packed PublicKey
 	PublicKeySize = internal.PublicKeySize
 
@@ -27,8 +30,32 @@ const (
 
 	// Size of a signature
 	SignatureSize = internal.SignatureSize
+
+	// Number of coefficients of a Poly
+	N = common.N
+
+	// Modulus of the coefficients of a Poly
+	Q = common.Q
+
+	// Base α = 2γ₂ of the decomposition computed by PolyDecompose
+	Alpha = common.Alpha
 )
 
+// Causes of verification failures returned by VerifyWithError.  They are
+// the same for all modes of Dilithium.
+var (
+	ErrBadSignatureLength = common.ErrBadSignatureLength
+	ErrZOutOfRange        = common.ErrZOutOfRange
+	ErrHintInvalid        = common.ErrHintInvalid
+	ErrChallengeMismatch  = common.ErrChallengeMismatch
+)
+
+// Poly is a polynomial of degree less than N with coefficients modulo Q,
+// as used internally by Dilithium1.  The functions on Poly are exposed for
+// inspecting signatures, for instance to recompute w₁ when debugging
+// interoperability, and compute exactly as signing and verification do.
+type Poly common.Poly
+
 // PublicKey is the type of Dilithium1 public key
 type PublicKey internal.PublicKey
 
@@ -68,7 +95,22 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 	)
 }
 
+// SignToWithRnd signs the given message as SignTo does, but mixes rnd
+// into the signing randomness if it is not nil.  Signatures with the same
+// rnd are reproducible, and those with a fresh rnd are randomized.
+// It will panic if signature is not of length at least SignatureSize.
+func SignToWithRnd(sk *PrivateKey, msg []byte, rnd *[RndSize]byte,
+	signature []byte) {
+	internal.SignToWithRnd(
+		(*internal.PrivateKey)(sk),
+		msg,
+		rnd,
+		signature,
+	)
+}
+
 // Verify checks whether the given signature by pk on msg is valid.
+// It returns false if signature is not of length SignatureSize.
 func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
 	return internal.Verify(
 		(*internal.PublicKey)(pk),
@@ -77,6 +119,25 @@ func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
 	)
 }
 
+// VerifyWithError checks whether the given signature by pk on msg is valid
+// as Verify does, and returns nil if so.  Otherwise, it returns the cause
+// of the failure: ErrBadSignatureLength, ErrZOutOfRange, ErrHintInvalid or
+// ErrChallengeMismatch.  This helps to locate interoperability issues.
+func VerifyWithError(pk *PublicKey, msg []byte, signature []byte) error {
+	return internal.VerifyWithError(
+		(*internal.PublicKey)(pk),
+		msg,
+		signature,
+	)
+}
+
+// SignatureEqual returns whether a and b are both canonical encodings of a
+// signature, and are equal.  Non-canonical inputs, such as malleated
+// signatures, are never equal to anything.
+func SignatureEqual(a, b []byte) bool {
+	return internal.SignatureEqual(a, b)
+}
+
 // Sets pk to the public key encoded in buf.
 func (pk *PublicKey) Unpack(buf *[PublicKeySize]byte) {
 	(*internal.PublicKey)(pk).Unpack(buf)
@@ -189,3 +250,35 @@ func (pk *PublicKey) Equal(other crypto.PublicKey) bool {
 	}
 	return (*internal.PublicKey)(pk).Equal((*internal.PublicKey)(castOther))
 }
+
+// PolyDecompose splits each coefficient a of p into a₁ and a₀ such that
+// a = a₁·α + a₀ with -α/2 < a₀ ≤ α/2, except when a₁ would be (Q-1)/α = 16,
+// in which case a₁ = 0 and -α/2 ≤ a₀ < 0.  It sets p1 to the a₁, which are
+// in [0, 16), and p0PlusQ to the a₀ + Q, which are in (Q-α/2, Q+α/2].
+//
+// Requires the coefficients of p to be in [0, Q).
+func PolyDecompose(p, p0PlusQ, p1 *Poly) {
+	(*common.Poly)(p).Decompose((*common.Poly)(p0PlusQ), (*common.Poly)(p1))
+}
+
+// PolyMakeHint sets hint to the hint polynomial, whose coefficients are 0
+// or 1, for p0 the modified low bits and p1 the unmodified high bits as
+// computed by PolyDecompose.  That is, for a coefficient r with high bits
+// r₁ and low bits r₀ and a small f with ‖f‖_∞ ≤ α/2, the hint for
+// r₀ - f (mod Q) and r₁ lets PolyUseHint recover r₁ from r - f (mod Q).
+//
+// Requires the coefficients of p0 to be in [0, Q) and those of p1 to be in
+// [0, 16).  Returns the number of ones in hint.
+func PolyMakeHint(hint, p0, p1 *Poly) uint32 {
+	return (*common.Poly)(hint).MakeHint((*common.Poly)(p0), (*common.Poly)(p1))
+}
+
+// PolyUseHint sets p to the high bits of q, as computed by PolyDecompose,
+// corrected according to hint, as computed by PolyMakeHint.  Returns p.
+//
+// Requires the coefficients of q to be in [0, Q) and those of hint to be
+// 0 or 1.
+func PolyUseHint(p, q, hint *Poly) *Poly {
+	(*common.Poly)(p).UseHint((*common.Poly)(q), (*common.Poly)(hint))
+	return p
+}
@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 375
 	Omega          = 64
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "df3cf0606f289ebc33312bea049a20a62fa30bf1e7731f0b281cd949dcb13358"
 )
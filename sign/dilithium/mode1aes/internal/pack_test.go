@@ -5,6 +5,7 @@ package internal
 import (
 	"testing"
 
+	"github.com/cloudflare/circl/internal/test"
 	"github.com/cloudflare/circl/sign/dilithium/internal/common"
 )
 
@@ -83,3 +84,31 @@ func TestPolyPackLeGamma1(t *testing.T) {
 		}
 	}
 }
+
+func TestPackHint(t *testing.T) {
+	var v, v2 VecK
+	var buf [Omega + K]byte
+
+	// Spreads ω ones over the polynomials, which fits exactly.
+	for i := 0; i < Omega; i++ {
+		v[i%K][(7*i)%common.N] = 1
+	}
+	v.PackHint(buf[:])
+	if !v2.UnpackHint(buf[:]) || v != v2 {
+		t.Fatalf("%v != %v", v, v2)
+	}
+
+	// One more one does not fit.
+	for j := 0; j < common.N; j++ {
+		if v[K-1][j] == 0 {
+			v[K-1][j] = 1
+			break
+		}
+	}
+	if err := test.CheckPanic(func() { v.PackHint(buf[:]) }); err != nil {
+		t.Fatal("PackHint does not panic on a hint with ω+1 ones")
+	}
+	if err := test.CheckPanic(func() { v2.PackHint(buf[:Omega+K-1]) }); err != nil {
+		t.Fatal("PackHint does not panic on a short buffer")
+	}
+}
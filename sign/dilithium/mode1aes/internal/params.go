@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 375
 	Omega          = 64
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "3ceeb9638cfec15eb6c1247eda8a217dabb1d06fcb5f24b986aaff6a6ed781ac"
 )
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"testing"
 
+	"github.com/cloudflare/circl/internal/sha3"
 	"github.com/cloudflare/circl/sign/dilithium/internal/common"
 )
 
@@ -405,3 +406,57 @@ func BenchmarkPolyDeriveUniformLeGamma1X4(b *testing.B) {
 			&seed, [4]uint16{nonce, nonce + 1, nonce + 2, nonce + 3})
 	}
 }
+
+// Reference implementation of ExpandMask, which reads the stream five bytes
+// at a time.
+func refDeriveUniformLeGamma1(p *common.Poly, seed *[48]byte, nonce uint16) {
+	var buf [5]byte
+	var read func()
+	if UseAES {
+		h := common.NewAesStream256(seed, nonce)
+		var block [160]byte
+		pos := len(block)
+		read = func() {
+			if pos == len(block) {
+				h.SqueezeInto(block[:])
+				pos = 0
+			}
+			copy(buf[:], block[pos:pos+5])
+			pos += 5
+		}
+	} else {
+		h := sha3.NewShake256()
+		_, _ = h.Write(seed[:])
+		_, _ = h.Write([]byte{byte(nonce), byte(nonce >> 8)})
+		read = func() { _, _ = h.Read(buf[:]) }
+	}
+
+	for i := 0; i < common.N; {
+		read()
+		x := uint64(buf[0]) | uint64(buf[1])<<8 | uint64(buf[2])<<16 |
+			uint64(buf[3])<<24 | uint64(buf[4])<<32
+		for _, t := range []uint32{uint32(x & 0xfffff), uint32(x >> 20)} {
+			if t <= 2*common.Gamma1-2 && i < common.N {
+				p[i] = common.Q + common.Gamma1 - 1 - t
+				i++
+			}
+		}
+	}
+}
+
+func TestDeriveUniformLeGamma1Reference(t *testing.T) {
+	var v, want VecL
+	var seed [48]byte
+	for i := 0; i < len(seed); i++ {
+		seed[i] = byte(i)
+	}
+	for nonce := uint16(0); nonce < 100; nonce += uint16(L) {
+		VecLDeriveUniformLeGamma1(&v, &seed, nonce)
+		for i := 0; i < L; i++ {
+			refDeriveUniformLeGamma1(&want[i], &seed, nonce+uint16(i))
+		}
+		if v != want {
+			t.Fatalf("%d\n%v\n%v", nonce, v, want)
+		}
+	}
+}
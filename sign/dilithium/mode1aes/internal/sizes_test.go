@@ -0,0 +1,48 @@
+// Code generated from mode3/internal/sizes_test.go by gen.go
+
+package internal
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/dilithium/internal/common"
+)
+
+// Checks that the size constants agree with the parameters, as the packing
+// functions rely on that.
+func TestSizeConstants(t *testing.T) {
+	// Size of a polynomial packed with the given bit length per coefficient.
+	packedSize := func(bitLen int) int { return common.N * bitLen / 8 }
+
+	for _, v := range []struct {
+		name      string
+		got, want int
+	}{
+		// Coefficients of norm ≤η are packed as η-x ∈ [0, 2η].
+		{"DoubleEtaBits", DoubleEtaBits, bits.Len(2 * Eta)},
+		{"PolyLeqEtaSize", PolyLeqEtaSize, packedSize(bits.Len(2 * Eta))},
+		// Coefficients of t₁ are the high bits of values in [0, q).
+		{"PolyT1Size", common.PolyT1Size,
+			packedSize(bits.Len((common.Q - 1) >> common.D))},
+		// Coefficients of t₀ are packed as 2ᵈ⁻¹-x ∈ [0, 2ᵈ).
+		{"PolyT0Size", common.PolyT0Size, packedSize(common.D)},
+		// Coefficients of norm <γ₁ are packed as γ₁-1-x ∈ [0, 2γ₁-2].
+		{"PolyLeGamma1Size", common.PolyLeGamma1Size,
+			packedSize(bits.Len(2*common.Gamma1 - 2))},
+		{"PolyLe16Size", common.PolyLe16Size, packedSize(bits.Len(15))},
+		// ρ ‖ t₁
+		{"PublicKeySize", PublicKeySize, 32 + K*common.PolyT1Size},
+		// ρ ‖ key ‖ tr ‖ s₁ ‖ s₂ ‖ t₀
+		{"PrivateKeySize", PrivateKeySize,
+			32 + 32 + 48 + (L+K)*PolyLeqEtaSize + K*common.PolyT0Size},
+		// z ‖ hint ‖ c, where the hint takes ω+k bytes and c 40 bytes.
+		{"SignatureSize", SignatureSize,
+			L*common.PolyLeGamma1Size + Omega + K + 40},
+	} {
+		if v.got != v.want {
+			t.Errorf("%s: %s = %d, but parameters imply %d",
+				Name, v.name, v.got, v.want)
+		}
+	}
+}
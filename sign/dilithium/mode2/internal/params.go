@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 325
 	Omega          = 80
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "7e56b0b283164481e6517c7dfc19b59da04ba85867530ebbbf2239bc8654957c"
 )
@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 325
 	Omega          = 80
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "4f8472900971cba5b5b027e36cf7970ac9f7132195d60ab9a4399d22db4c19a4"
 )
@@ -0,0 +1,47 @@
+package mode3
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/sign/dilithium/internal/common"
+)
+
+func randPoly(p *Poly) {
+	for i := range p {
+		p[i] = rand.Uint32() % Q
+	}
+}
+
+func TestPolyRounding(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var p, q, hint Poly
+		randPoly(&p)
+		randPoly(&q)
+		for j := range hint {
+			hint[j] = rand.Uint32() & 1
+		}
+
+		var p0, p1, want0, want1 common.Poly
+		PolyDecompose(&p, (*Poly)(&p0), (*Poly)(&p1))
+		(*common.Poly)(&p).Decompose(&want0, &want1)
+		if p0 != want0 || p1 != want1 {
+			test.ReportError(t, p1, want1, p)
+		}
+
+		var h, wantH common.Poly
+		pop := PolyMakeHint((*Poly)(&h), &q, (*Poly)(&p1))
+		wantPop := wantH.MakeHint((*common.Poly)(&q), &p1)
+		if h != wantH || pop != wantPop {
+			test.ReportError(t, h, wantH, q, p1)
+		}
+
+		var w1, wantW1 common.Poly
+		PolyUseHint((*Poly)(&w1), &q, &hint)
+		wantW1.UseHint((*common.Poly)(&q), (*common.Poly)(&hint))
+		if w1 != wantW1 {
+			test.ReportError(t, w1, wantW1, q, hint)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package mode3_test
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/dilithium/mode3"
+)
+
+func ExamplePolyDecompose() {
+	var p, p0PlusQ, p1 mode3.Poly
+	for i := range p {
+		p[i] = uint32(i) * 32749 % mode3.Q
+	}
+
+	mode3.PolyDecompose(&p, &p0PlusQ, &p1)
+
+	// Recompose each coefficient as a₁·α + a₀ (mod Q).
+	ok := true
+	for i := range p {
+		a := (p1[i]*mode3.Alpha + p0PlusQ[i]) % mode3.Q
+		ok = ok && a == p[i] && p1[i] < 16
+	}
+	fmt.Println(ok)
+	// Output: true
+}
+
+func ExamplePolyUseHint() {
+	// r is a polynomial and f a small one, with ‖f‖_∞ ≤ α/2.
+	var r, f mode3.Poly
+	for i := range r {
+		r[i] = uint32(i) * 32749 % mode3.Q
+		f[i] = uint32(i) * 1021 % (mode3.Alpha / 2)
+	}
+
+	// The hint is computed from the decomposition of r, and the low bits
+	// r₀ - f (mod Q).
+	var r0PlusQ, r1, z0, hint mode3.Poly
+	mode3.PolyDecompose(&r, &r0PlusQ, &r1)
+	for i := range z0 {
+		z0[i] = (r0PlusQ[i] + mode3.Q - f[i]) % mode3.Q
+	}
+	pop := mode3.PolyMakeHint(&hint, &z0, &r1)
+
+	// The hint recovers the high bits of r from r - f (mod Q) only.
+	var rp, w1 mode3.Poly
+	for i := range rp {
+		rp[i] = (r[i] + mode3.Q - f[i]) % mode3.Q
+	}
+	mode3.PolyUseHint(&w1, &rp, &hint)
+
+	fmt.Println(w1 == r1, pop > 0)
+	// Output: true true
+}
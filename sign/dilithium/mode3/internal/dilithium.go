@@ -1,9 +1,11 @@
 package internal
 
 import (
+	"bytes"
 	cryptoRand "crypto/rand"
 	"crypto/subtle"
 	"io"
+	"sync"
 
 	"github.com/cloudflare/circl/internal/sha3"
 	"github.com/cloudflare/circl/sign/dilithium/internal/common"
@@ -22,7 +24,7 @@ type PublicKey struct {
 
 	// Cached values
 	t1p [common.PolyT1Size * K]byte
-	A   *Mat
+	A   *Mat // shared with matCache or a PrivateKey, never modified
 	tr  *[48]byte
 }
 
@@ -59,18 +61,27 @@ func (sig *unpackedSignature) Pack(buf []byte) {
 //
 // Returns whether buf contains a properly packed signature.
 func (sig *unpackedSignature) Unpack(buf []byte) bool {
-	if len(buf) < SignatureSize {
-		return false
+	return sig.unpack(buf) == nil
+}
+
+// Sets sig to the signature encoded in the buffer, as Unpack does.
+//
+// Returns why buf does not contain a properly packed signature, if so.
+func (sig *unpackedSignature) unpack(buf []byte) error {
+	if len(buf) != SignatureSize {
+		return common.ErrBadSignatureLength
+	}
+	if !sig.z.UnpackLeGamma1Checked(buf[:]) {
+		return common.ErrZOutOfRange
 	}
-	sig.z.UnpackLeGamma1(buf[:])
 	if sig.z.Exceeds(common.Gamma1 - Beta) {
-		return false
+		return common.ErrZOutOfRange
 	}
 	if !sig.hint.UnpackHint(buf[L*common.PolyLeGamma1Size:]) {
-		return false
+		return common.ErrHintInvalid
 	}
 	sig.c.UnpackB60(buf[L*common.PolyLeGamma1Size+Omega+K:])
-	return true
+	return nil
 }
 
 // Packs the public key into buf.
@@ -85,8 +96,7 @@ func (pk *PublicKey) Unpack(buf *[PublicKeySize]byte) {
 	copy(pk.t1p[:], buf[32:])
 
 	pk.t1.UnpackT1(pk.t1p[:])
-	pk.A = new(Mat)
-	pk.A.Derive(&pk.rho)
+	pk.A = matCache.get(&pk.rho)
 
 	// tr = CRH(ρ ‖ t1) = CRH(pk)
 	pk.tr = new([48]byte)
@@ -225,18 +235,65 @@ func NewKeyFromSeed(seed *[common.SeedSize]byte) (*PublicKey, *PrivateKey) {
 	return NewKeyFromExpandedSeed(&buf)
 }
 
+// SignatureEqual returns whether a and b are both canonical encodings of a
+// signature, and are equal.  A signature is canonical if packing it again
+// after unpacking gives the same bytes, and it passes the range checks of
+// Verify.  A non-canonical input is never equal to anything.
+func SignatureEqual(a, b []byte) bool {
+	return isCanonicalSignature(a) && isCanonicalSignature(b) &&
+		bytes.Equal(a, b)
+}
+
+// Returns whether buf is the canonical encoding of a signature.
+func isCanonicalSignature(buf []byte) bool {
+	var sig unpackedSignature
+	if !sig.Unpack(buf) {
+		return false
+	}
+	var packed [SignatureSize]byte
+	sig.Pack(packed[:])
+	return bytes.Equal(packed[:], buf)
+}
+
 // Verify checks whether the given signature by pk on msg is valid.
+// It returns false if signature is not of length SignatureSize.
 func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
+	return VerifyWithError(pk, msg, signature) == nil
+}
+
+// VerifyWithError checks whether the given signature by pk on msg is
+// valid as Verify does, and returns nil if so.  Otherwise, it returns
+// common.ErrBadSignatureLength, common.ErrZOutOfRange,
+// common.ErrHintInvalid or common.ErrChallengeMismatch, depending on the
+// first check that failed.
+func VerifyWithError(pk *PublicKey, msg []byte, signature []byte) error {
 	var sig unpackedSignature
+	var cp common.Poly
+	if err := recoverChallenge(pk, msg, signature, &sig, &cp); err != nil {
+		return err
+	}
+	if sig.c != cp {
+		return common.ErrChallengeMismatch
+	}
+	return nil
+}
+
+// recoverChallenge unpacks signature into sig and recomputes from it the
+// challenge c' into cp.  The signature is valid if and only if this returns
+// nil and sig.c equals cp.
+//
+// Returns the reason why signature is malformed, if so.
+func recoverChallenge(pk *PublicKey, msg []byte, signature []byte,
+	sig *unpackedSignature, cp *common.Poly) error {
 	var mu [48]byte
 	var zh VecL
 	var Az, Az2dct1, w1 VecK
-	var ch, cp common.Poly
+	var ch common.Poly
 
-	// Note that Unpack() checked whether ‖z‖_∞ < γ₁ - β
-	// and ensured that there at most ω ones in pk.hint.
-	if !sig.Unpack(signature) {
-		return false
+	// Note that unpack() checks the length of signature, whether
+	// ‖z‖_∞ < γ₁ - β and ensures that there at most ω ones in pk.hint.
+	if err := sig.unpack(signature); err != nil {
+		return err
 	}
 
 	// μ = CRH(tr ‖ msg)
@@ -276,13 +333,69 @@ func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
 	w1.UseHint(&Az2dct1, &sig.hint)
 
 	// c' = H(μ, w₁)
-	PolyDeriveUniformB60(&cp, &mu, &w1)
-	return sig.c == cp
+	PolyDeriveUniformB60(cp, &mu, &w1)
+	return nil
+}
+
+// signState holds the scratch space of SignToWithRnd that would otherwise
+// escape to the heap on each call, such as the SHAKE-256 instance.  It is
+// kept in signStatePool, so that repeated signing reuses it, and it is
+// zeroed before being put back, as it holds values derived from the
+// private key.
+//
+// With AES, the expansion of the mask still allocates its cipher.
+type signState struct {
+	h        sha3.State
+	mu, rhop [48]byte
+	iv       [48 + 2]byte
+	w1Packed [common.PolyLe16Size * K]byte
+	buf      [136]byte // SHAKE-256 rate is 136
+}
+
+var signStatePool = sync.Pool{
+	New: func() interface{} { return new(signState) },
+}
+
+// deriveMaskSeed computes μ = CRH(tr ‖ msg) into mu, and the seed of the
+// mask y, ρ' = CRH(key ‖ μ) or CRH(key ‖ rnd ‖ μ) if rnd is given, into
+// rhop, using h as scratch space.  The mask, and thus the signature, is
+// only secure if ρ' is unique to the message and rnd, which the tests
+// check through this function.
+func deriveMaskSeed(h *sha3.State, sk *PrivateKey, msg []byte,
+	rnd *[common.RndSize]byte, mu, rhop *[48]byte) {
+	*h = sha3.NewShake256()
+	_, _ = h.Write(sk.tr[:])
+	_, _ = h.Write(msg)
+	_, _ = h.Read(mu[:])
+
+	h.Reset()
+	_, _ = h.Write(sk.key[:])
+	if rnd != nil {
+		_, _ = h.Write(rnd[:])
+	}
+	_, _ = h.Write(mu[:])
+	_, _ = h.Read(rhop[:])
 }
 
 // SignTo signs the given message and writes the signature into signature.
+//
+// Signing is a rejection loop, so the number of iterations varies and
+// leaks through timing.  This is inherent to the Fiat-Shamir with aborts
+// construction: the rejected candidates are discarded and not related to
+// the published signature.  Within an iteration, the expansion of the mask
+// y and the arithmetic on secret polynomials do not branch on secret data.
+// The norm checks compute the norms of whole vectors with Norm(), so they
+// do not leak which coefficient caused a rejection.
 func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
-	var mu, rhop [48]byte
+	SignToWithRnd(sk, msg, nil, signature)
+}
+
+// SignToWithRnd is like SignTo, but if rnd is not nil, it is mixed into the
+// derivation of the mask y, which lets the caller choose between
+// deterministic signatures, with a fixed rnd, and randomized ones, with a
+// fresh rnd.  If rnd is nil, the signature is the same as that of SignTo.
+func SignToWithRnd(sk *PrivateKey, msg []byte, rnd *[common.RndSize]byte,
+	signature []byte) {
 	var y, yh VecL
 	var w, w0, w1, w0mcs2, ct0, w0mcs2pct0 VecK
 	var ch common.Poly
@@ -293,17 +406,13 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		panic("Signature does not fit in that byteslice")
 	}
 
-	//  μ = CRH(tr ‖ msg)
-	h := sha3.NewShake256()
-	_, _ = h.Write(sk.tr[:])
-	_, _ = h.Write(msg)
-	_, _ = h.Read(mu[:])
-
-	// ρ' = CRH(μ ‖ key)
-	h.Reset()
-	_, _ = h.Write(sk.key[:])
-	_, _ = h.Write(mu[:])
-	_, _ = h.Read(rhop[:])
+	st := signStatePool.Get().(*signState)
+	defer func() {
+		*st = signState{}
+		signStatePool.Put(st)
+	}()
+	mu, rhop := &st.mu, &st.rhop
+	deriveMaskSeed(&st.h, sk, msg, rnd, mu, rhop)
 
 	// Main rejection loop
 	attempt := 0
@@ -317,7 +426,7 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		}
 
 		// y = ExpandMask(ρ', key)
-		VecLDeriveUniformLeGamma1(&y, &rhop, yNonce)
+		vecLDeriveUniformLeGamma1(&y, rhop, yNonce, st)
 		yNonce += uint16(L)
 
 		// Set w to A y
@@ -334,7 +443,7 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		w.Decompose(&w0, &w1)
 
 		// c = H(μ, w₁)
-		PolyDeriveUniformB60(&sig.c, &mu, &w1)
+		polyDeriveUniformB60(&sig.c, mu, &w1, st)
 		ch = sig.c
 		ch.NTT()
 
@@ -351,7 +460,7 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		w0mcs2.Sub(&w0, &w0mcs2)
 		w0mcs2.Normalize()
 
-		if w0mcs2.Exceeds(common.Gamma2 - Beta) {
+		if w0mcs2.Norm() >= common.Gamma2-Beta {
 			continue
 		}
 
@@ -364,7 +473,7 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		sig.z.Normalize()
 
 		// Ensure  ‖z‖_∞ < γ₁ - β
-		if sig.z.Exceeds(common.Gamma1 - Beta) {
+		if sig.z.Norm() >= common.Gamma1-Beta {
 			continue
 		}
 
@@ -376,7 +485,7 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 		ct0.NormalizeAssumingLe2Q()
 
 		// Ensure ‖c·t₀‖_∞ < γ₂.
-		if ct0.Exceeds(common.Gamma2) {
+		if ct0.Norm() >= common.Gamma2 {
 			continue
 		}
 
@@ -0,0 +1,20 @@
+// +build gofuzz
+
+// How to run the fuzzer:
+//  $ go get -u github.com/dvyukov/go-fuzz/go-fuzz
+//  $ go get -u github.com/dvyukov/go-fuzz/go-fuzz-build
+//  $ go-fuzz-build -libfuzzer -func FuzzVerify -o lib.a
+//  $ clang -fsanitize=fuzzer lib.a -o fu.exe
+//  $ ./fu.exe
+package internal
+
+// FuzzVerify is a fuzzer target for Verify, which must reject malformed
+// signatures of any length without panicking.
+func FuzzVerify(data []byte) int {
+	var seed [32]byte
+	pk, _ := NewKeyFromSeed(&seed)
+	if Verify(pk, []byte("message"), data) {
+		panic("forged signature accepted")
+	}
+	return 1
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"container/list"
+	"sync"
+)
+
+// matCacheSize is the number of matrices kept in matCache.
+const matCacheSize = 8
+
+// matCache keeps ExpandA(ρ) for the ρ of the most recently unpacked public
+// keys, so that unpacking a public key again, for instance, to verify a
+// batch of signatures under the same key, does not expand its matrix again.
+var matCache = newMatLRU(matCacheSize)
+
+// matLRU is a cache of expanded matrices keyed by their seed ρ, which holds
+// at most a fixed number of matrices, evicting the least recently used one.
+// It is safe for concurrent use by multiple goroutines.
+//
+// The returned matrices are shared, so they must not be modified.
+type matLRU struct {
+	mu   sync.Mutex
+	size int
+	lru  *list.List // of *cachedMat, most recently used first.
+	mats map[[32]byte]*list.Element
+}
+
+type cachedMat struct {
+	rho [32]byte
+	A   Mat
+}
+
+func newMatLRU(size int) *matLRU {
+	return &matLRU{
+		size: size,
+		lru:  list.New(),
+		mats: make(map[[32]byte]*list.Element, size),
+	}
+}
+
+// Returns ExpandA(ρ), expanding and caching it on a miss.
+func (c *matLRU) get(rho *[32]byte) *Mat {
+	c.mu.Lock()
+	if e, ok := c.mats[*rho]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return &e.Value.(*cachedMat).A
+	}
+	c.mu.Unlock()
+
+	// Expand without holding the lock, so that misses do not serialize.
+	m := &cachedMat{rho: *rho}
+	m.A.Derive(rho)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.mats[*rho]; ok {
+		// Another goroutine cached the same matrix meanwhile.
+		c.lru.MoveToFront(e)
+		return &e.Value.(*cachedMat).A
+	}
+	c.mats[*rho] = c.lru.PushFront(m)
+	if c.lru.Len() > c.size {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.mats, last.Value.(*cachedMat).rho)
+	}
+	return &m.A
+}
@@ -71,7 +71,9 @@ func PolyUnpackLeqEta(p *common.Poly, buf []byte) {
 }
 
 // Writes v with coefficients in {0, 1} of which at most ω non-zero
-// to buf, which must have length ω+k.
+// to buf, which must have length at least ω+k.
+//
+// Panics if buf is too short or v has more than ω non-zero coefficients.
 func (v *VecK) PackHint(buf []byte) {
 	// The packed hint starts with the indices of the non-zero coefficients
 	// For instance:
@@ -90,10 +92,17 @@ func (v *VecK) PackHint(buf []byte) {
 	//
 	//  56, 100, 255, 2, 23, 1, 0, 0, ..., 0, 2, 3, 3, 5, 6
 
+	if len(buf) < Omega+K {
+		panic("hint buffer must have length at least ω+k")
+	}
+
 	off := uint8(0)
 	for i := 0; i < K; i++ {
 		for j := uint16(0); j < common.N; j++ {
 			if v[i][j] != 0 {
+				if off == Omega {
+					panic("hint has more than ω non-zero coefficients")
+				}
 				buf[off] = uint8(j)
 				off++
 			}
@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 275
 	Omega          = 96
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "5fcc0312e1443c6982f5a99791555d582719481c175760c84eb8b52cffe0e9f0"
 )
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/cloudflare/circl/internal/sha3"
+	"github.com/cloudflare/circl/sign/dilithium/internal/common"
+)
+
+// selfTestMsg is the message signed by the self-test.
+const selfTestMsg = "CIRCL Dilithium self-test"
+
+var errSelfTest = errors.New("dilithium: " + Name + " self-test failed")
+
+// selfTest signs a fixed message with the key derived from a fixed seed,
+// and checks that the SHAKE-256 digest of the signature is the
+// hex-encoded want, and that the signature verifies.
+func selfTest(want string) error {
+	var seed [common.SeedSize]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	pk, sk := NewKeyFromSeed(&seed)
+
+	var sig [SignatureSize]byte
+	SignTo(sk, []byte(selfTestMsg), sig[:])
+
+	var digest [32]byte
+	h := sha3.NewShake256()
+	_, _ = h.Write(sig[:])
+	_, _ = h.Read(digest[:])
+	if hex.EncodeToString(digest[:]) != want {
+		return errSelfTest
+	}
+	if !Verify(pk, []byte(selfTestMsg), sig[:]) {
+		return errSelfTest
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// +build selftest
+
+package internal
+
+// Runs a known-answer test before the mode is used when building with the
+// selftest tag, as required by power-on self-test policies.
+func init() {
+	if err := selfTest(SelfTestDigest); err != nil {
+		panic(err)
+	}
+}
@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 4
 	Beta           = 275
 	Omega          = 96
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "eeda2fa9b7d5589a1210cf2becc62eae4713bf7267bb3c93d8e3ea1a1126673c"
 )
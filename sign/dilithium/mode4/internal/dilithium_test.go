@@ -3,7 +3,11 @@
 package internal
 
 import (
+	"bytes"
+	"crypto/rand"
 	"encoding/binary"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/cloudflare/circl/sign/dilithium/internal/common"
@@ -16,6 +20,22 @@ func PolyNormalized(p *common.Poly) bool {
 	return p2 == *p
 }
 
+// Verifies signature like Verify, but also returns the challenge c'
+// recomputed from it, packed with PackB60.  Only used in tests to localize
+// where verification diverges, for instance against the reference
+// implementation.
+func verifyWithChallenge(pk *PublicKey, msg, signature []byte) (
+	cMatches bool, recomputed []byte) {
+	var sig unpackedSignature
+	var cp common.Poly
+	if recoverChallenge(pk, msg, signature, &sig, &cp) != nil {
+		return false, nil
+	}
+	recomputed = make([]byte, 40)
+	cp.PackB60(recomputed)
+	return sig.c == cp, recomputed
+}
+
 func BenchmarkSkUnpack(b *testing.B) {
 	var buf [PrivateKeySize]byte
 	var sk PrivateKey
@@ -51,6 +71,35 @@ func BenchmarkVerify(b *testing.B) {
 	}
 }
 
+func BenchmarkVerifyBatch(b *testing.B) {
+	// Verifies a batch of signatures under one key, unpacking the key for
+	// each signature as callers holding packed keys do.
+	var seed [32]byte
+	var msg [8]byte
+	var sig [SignatureSize]byte
+	var ppk [PublicKeySize]byte
+	pk, sk := NewKeyFromSeed(&seed)
+	SignTo(sk, msg[:], sig[:])
+	pk.Pack(&ppk)
+
+	b.Run("Uncached", func(b *testing.B) {
+		var pk PublicKey
+		for i := 0; i < b.N; i++ {
+			pk.Unpack(&ppk)
+			pk.A = new(Mat)
+			pk.A.Derive(&pk.rho)
+			Verify(&pk, msg[:], sig[:])
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		var pk PublicKey
+		for i := 0; i < b.N; i++ {
+			pk.Unpack(&ppk)
+			Verify(&pk, msg[:], sig[:])
+		}
+	})
+}
+
 func BenchmarkSign(b *testing.B) {
 	// Note that the expansion of the matrix A is done at Unpacking/Keygen
 	// instead of at the moment of signing (as in the reference implementation.)
@@ -58,6 +107,7 @@ func BenchmarkSign(b *testing.B) {
 	var msg [8]byte
 	var sig [SignatureSize]byte
 	_, sk := NewKeyFromSeed(&seed)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		binary.LittleEndian.PutUint64(msg[:], uint64(i))
@@ -129,3 +179,293 @@ func TestPublicFromPrivate(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyWithChallenge(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var sig [SignatureSize]byte
+	var msg [8]byte
+	pk, sk := NewKeyFromSeed(&seed)
+	SignTo(sk, msg[:], sig[:])
+
+	// The packed challenge is the last part of the signature.
+	ok, c := verifyWithChallenge(pk, msg[:], sig[:])
+	if !ok || !bytes.Equal(c, sig[SignatureSize-len(c):]) {
+		t.Fatal("recomputed challenge differs for valid signature")
+	}
+
+	msg[0] ^= 1
+	ok, c2 := verifyWithChallenge(pk, msg[:], sig[:])
+	if ok || bytes.Equal(c, c2) {
+		t.Fatal("recomputed challenge matches for tampered message")
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var sig [SignatureSize + 1]byte
+	var msg [8]byte
+	pk, sk := NewKeyFromSeed(&seed)
+	SignTo(sk, msg[:], sig[:])
+
+	for _, l := range []int{0, 1, SignatureSize - 1, SignatureSize + 1} {
+		if Verify(pk, msg[:], sig[:l]) {
+			t.Fatalf("signature of length %d accepted", l)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		buf := make([]byte, SignatureSize)
+		_, _ = rand.Read(buf)
+		if Verify(pk, msg[:], buf) {
+			t.Fatal("random signature accepted")
+		}
+	}
+}
+
+func TestSignToWithRnd(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var rnd, rnd2 [common.RndSize]byte
+	var sig, sig2, sig3 [SignatureSize]byte
+	var msg [8]byte
+	pk, sk := NewKeyFromSeed(&seed)
+
+	// Without rnd, it is the same as SignTo.
+	SignTo(sk, msg[:], sig[:])
+	SignToWithRnd(sk, msg[:], nil, sig2[:])
+	if sig != sig2 {
+		t.Fatal("SignToWithRnd without rnd differs from SignTo")
+	}
+
+	for i := uint64(0); i < 10; i++ {
+		binary.LittleEndian.PutUint64(rnd[:], i)
+		binary.LittleEndian.PutUint64(rnd2[:], i+1)
+		SignToWithRnd(sk, msg[:], &rnd, sig[:])
+		SignToWithRnd(sk, msg[:], &rnd, sig2[:])
+		SignToWithRnd(sk, msg[:], &rnd2, sig3[:])
+		if sig != sig2 {
+			t.Fatal("signatures with equal rnd differ")
+		}
+		if sig == sig3 {
+			t.Fatal("signatures with different rnd are equal")
+		}
+		if !Verify(pk, msg[:], sig[:]) || !Verify(pk, msg[:], sig3[:]) {
+			t.Fatal("signature with rnd does not verify")
+		}
+	}
+}
+
+// maskOf returns the first mask y used to sign msg with sk and rnd.
+func maskOf(sk *PrivateKey, msg []byte, rnd *[common.RndSize]byte) VecL {
+	var st signState
+	var y VecL
+	deriveMaskSeed(&st.h, sk, msg, rnd, &st.mu, &st.rhop)
+	vecLDeriveUniformLeGamma1(&y, &st.rhop, 0, &st)
+	return y
+}
+
+func TestMaskUniqueness(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var rnd [common.RndSize]byte
+	var sig, sig2 [SignatureSize]byte
+	_, sk := NewKeyFromSeed(&seed)
+
+	// Deterministic signatures of the same message are equal.
+	msg := []byte("message")
+	SignTo(sk, msg, sig[:])
+	SignTo(sk, msg, sig2[:])
+	if sig != sig2 {
+		t.Fatal("deterministic signatures of the same message differ")
+	}
+	if maskOf(sk, msg, nil) != maskOf(sk, msg, nil) {
+		t.Fatal("masks of the same message differ")
+	}
+
+	// The masks of different messages, or rnd, never repeat.
+	masks := make(map[VecL]int)
+	for i := 0; i < 32; i++ {
+		binary.LittleEndian.PutUint64(rnd[:], uint64(i))
+		for _, y := range []VecL{
+			maskOf(sk, []byte{byte(i)}, nil),
+			maskOf(sk, []byte{byte(i)}, &rnd),
+			maskOf(sk, msg, &rnd),
+		} {
+			if j, ok := masks[y]; ok {
+				t.Fatalf("mask %v repeats mask %v", len(masks), j)
+			}
+			masks[y] = len(masks)
+		}
+	}
+}
+
+func TestSignState(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var sig [SignatureSize]byte
+	var msg [8]byte
+	_, sk := NewKeyFromSeed(&seed)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		SignTo(sk, msg[:], sig[:])
+	})
+	if allocs >= 1 && !UseAES {
+		t.Fatalf("SignTo allocates %v times", allocs)
+	}
+
+	// The scratch space is zeroed before it is put back into the pool.
+	SignTo(sk, msg[:], sig[:])
+	st := signStatePool.Get().(*signState)
+	if !reflect.DeepEqual(*st, signState{}) {
+		t.Fatal("scratch space of SignTo is not zeroed")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := selfTest(SelfTestDigest); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := []byte(SelfTestDigest)
+	corrupted[0] ^= 1
+	if err := selfTest(string(corrupted)); err == nil {
+		t.Fatal("self-test passes with a corrupted expected value")
+	}
+}
+
+func TestSignatureEqual(t *testing.T) {
+	var seed [32]byte
+	pk, sk := NewKeyFromSeed(&seed)
+	var sig, sig2 [SignatureSize]byte
+	SignTo(sk, []byte("message"), sig[:])
+	SignTo(sk, []byte("other message"), sig2[:])
+
+	cp := sig
+	if !SignatureEqual(sig[:], cp[:]) {
+		t.Fatal("signature must equal its copy")
+	}
+	if SignatureEqual(sig[:], sig2[:]) {
+		t.Fatal("signatures of distinct messages must differ")
+	}
+	if SignatureEqual(sig[:], sig[:SignatureSize-1]) {
+		t.Fatal("truncated signature must not be equal")
+	}
+
+	// Malleate the signature by setting a padding index of the hint, which
+	// must be zero.
+	hint := sig[L*common.PolyLeGamma1Size:]
+	if hint[Omega+K-1] == Omega {
+		t.Skip("hint without padding")
+	}
+	hint[Omega-1] = 1
+	if Verify(pk, []byte("message"), sig[:]) {
+		t.Fatal("malleated signature must not verify")
+	}
+	if SignatureEqual(sig[:], sig[:]) {
+		t.Fatal("malleated signature must not be equal to itself")
+	}
+}
+
+func TestVerifyWithError(t *testing.T) {
+	var seed [common.SeedSize]byte
+	var sig [SignatureSize]byte
+	msg := []byte("message")
+	pk, sk := NewKeyFromSeed(&seed)
+	SignTo(sk, msg, sig[:])
+
+	if err := VerifyWithError(pk, msg, sig[:]); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+
+	hintOff := L * common.PolyLeGamma1Size
+	tests := []struct {
+		name   string
+		mutate func(s []byte) []byte
+		want   error
+	}{
+		{"truncated", func(s []byte) []byte {
+			return s[:SignatureSize-1]
+		}, common.ErrBadSignatureLength},
+		{"extended", func(s []byte) []byte {
+			return append(s, 0)
+		}, common.ErrBadSignatureLength},
+		{"z out of range", func(s []byte) []byte {
+			// Packs γ₁-1 as the first coefficient of z.
+			s[0], s[1], s[2] = 0, 0, s[2]&0xf0
+			return s
+		}, common.ErrZOutOfRange},
+		{"hint switch-over point", func(s []byte) []byte {
+			s[hintOff+Omega+K-1] = Omega + 1
+			return s
+		}, common.ErrHintInvalid},
+		{"challenge", func(s []byte) []byte {
+			s[SignatureSize-1] ^= 1
+			return s
+		}, common.ErrChallengeMismatch},
+	}
+	for _, tt := range tests {
+		s := tt.mutate(append([]byte{}, sig[:]...))
+		if err := VerifyWithError(pk, msg, s); err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		if Verify(pk, msg, s) {
+			t.Errorf("%s: mutated signature accepted", tt.name)
+		}
+	}
+
+	if err := VerifyWithError(pk, []byte("other message"), sig[:]); err != common.ErrChallengeMismatch {
+		t.Fatalf("wrong message: got %v", err)
+	}
+}
+
+func TestMatCache(t *testing.T) {
+	c := newMatLRU(2)
+	var rhos [3][32]byte
+	var want [3]Mat
+	for i := range rhos {
+		rhos[i][0] = byte(i)
+		want[i].Derive(&rhos[i])
+	}
+
+	// Hits return the same matrix.
+	A := c.get(&rhos[0])
+	if *A != want[0] || c.get(&rhos[0]) != A {
+		t.Fatal("cached matrix differs")
+	}
+
+	// The least recently used matrix is evicted.
+	_ = c.get(&rhos[1])
+	_ = c.get(&rhos[0])
+	_ = c.get(&rhos[2])
+	if c.lru.Len() != 2 {
+		t.Fatalf("cache holds %v matrices", c.lru.Len())
+	}
+	if _, ok := c.mats[rhos[1]]; ok {
+		t.Fatal("least recently used matrix is not evicted")
+	}
+	if c.get(&rhos[0]) != A || *c.get(&rhos[1]) != want[1] {
+		t.Fatal("cached matrix differs")
+	}
+
+	// Concurrent lookups agree.
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			if *c.get(&rhos[g%3]) != want[g%3] {
+				t.Error("cached matrix differs")
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Unpacked public keys use the cache.
+	var seed [32]byte
+	var ppk [PublicKeySize]byte
+	pk, _ := NewKeyFromSeed(&seed)
+	pk.Pack(&ppk)
+	var pk1, pk2 PublicKey
+	pk1.Unpack(&ppk)
+	pk2.Unpack(&ppk)
+	if pk1.A != pk2.A || *pk1.A != *pk.A {
+		t.Fatal("unpacked public keys do not share their matrix")
+	}
+}
@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 3
 	Beta           = 175
 	Omega          = 120
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "1740c9933d6d33f74f466ba2199c6b0f90b6a5139ee3390e4f621096fbc0a924"
 )
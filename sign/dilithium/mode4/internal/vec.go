@@ -53,6 +53,20 @@ func (v *VecL) Exceeds(bound uint32) bool {
 	return false
 }
 
+// Returns the largest supnorm of the polynomials of v, without leaking which
+// is the largest.  See Poly.Norm().
+//
+// Requires the vector to be normalized.
+func (v *VecL) Norm() uint32 {
+	var m uint32
+	for i := 0; i < L; i++ {
+		x := v[i].Norm()
+		mask := uint32((int32(m) - int32(x)) >> 31)
+		m ^= (m ^ x) & mask
+	}
+	return m
+}
+
 // Applies Poly.Power2Round componentwise.
 //
 // Requires the vector to be normalized.
@@ -107,6 +121,18 @@ func (v *VecL) UnpackLeGamma1(buf []byte) {
 	}
 }
 
+// Sets v to the polynomials packed in buf using VecL.PackLeGamma1(), and
+// returns whether their coefficients are all less than γ₁ in norm.
+func (v *VecL) UnpackLeGamma1Checked(buf []byte) bool {
+	ret := true
+	offset := 0
+	for i := 0; i < L; i++ {
+		ret = v[i].UnpackLeGamma1Checked(buf[offset:]) && ret
+		offset += common.PolyLeGamma1Size
+	}
+	return ret
+}
+
 // Normalize the polynomials in this vector.
 func (v *VecK) Normalize() {
 	for i := 0; i < K; i++ {
@@ -141,6 +167,20 @@ func (v *VecK) Exceeds(bound uint32) bool {
 	return false
 }
 
+// Returns the largest supnorm of the polynomials of v, without leaking which
+// is the largest.  See Poly.Norm().
+//
+// Requires the vector to be normalized.
+func (v *VecK) Norm() uint32 {
+	var m uint32
+	for i := 0; i < K; i++ {
+		x := v[i].Norm()
+		mask := uint32((int32(m) - int32(x)) >> 31)
+		m ^= (m ^ x) & mask
+	}
+	return m
+}
+
 // Applies Poly.Power2Round componentwise.
 //
 // Requires the vector to be normalized.
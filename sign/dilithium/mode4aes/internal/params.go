@@ -14,4 +14,8 @@ const (
 	DoubleEtaBits  = 3
 	Beta           = 175
 	Omega          = 120
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "b3269898c3f5e128bf9d13b4a52917b1987a95bbe4086aa24f5d913ae8060ab0"
 )
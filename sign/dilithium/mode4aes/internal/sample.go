@@ -91,14 +91,13 @@ func PolyDeriveUniformLeGamma1X4(ps [4]*common.Poly, seed *[48]byte,
 					carry[j] = uint32(qw >> (64 - shift[j]))
 				}
 
-				// Check if they're coefficients.
+				// Check if they're coefficients.  See the comment in
+				// PolyDeriveUniformLeGamma1 on why this does not branch.
 				for k := 0; k < tCount; k++ {
-					if t[k] <= 2*common.Gamma1-2 {
-						ps[j][idx[j]] = common.Q + common.Gamma1 - 1 - t[k]
-						idx[j]++
-						if idx[j] == common.N {
-							continue PolyLoop
-						}
+					ps[j][idx[j]] = common.Q + common.Gamma1 - 1 - t[k]
+					idx[j] += int(1 ^ ((2*common.Gamma1 - 2 - t[k]) >> 31))
+					if idx[j] == common.N {
+						continue PolyLoop
 					}
 				}
 			}
@@ -297,9 +296,16 @@ func PolyDeriveUniformLeqEta(p *common.Poly, seed *[32]byte, nonce uint16) {
 // v[i] will not be normalized, but have coefficients in the
 // interval (q-γ₁,q+γ₁).
 func VecLDeriveUniformLeGamma1(v *VecL, seed *[48]byte, nonce uint16) {
+	var st signState
+	vecLDeriveUniformLeGamma1(v, seed, nonce, &st)
+}
+
+// Like VecLDeriveUniformLeGamma1, but uses the scratch space of st.
+func vecLDeriveUniformLeGamma1(v *VecL, seed *[48]byte, nonce uint16,
+	st *signState) {
 	if !DeriveX4Available {
 		for i := 0; i < L; i++ {
-			PolyDeriveUniformLeGamma1(&v[i], seed, nonce+uint16(i))
+			polyDeriveUniformLeGamma1(&v[i], seed, nonce+uint16(i), st)
 		}
 		return
 	}
@@ -314,18 +320,33 @@ func VecLDeriveUniformLeGamma1(v *VecL, seed *[48]byte, nonce uint16) {
 	// PolyDeriveUniformLeGamma.
 	PolyDeriveUniformLeGamma1X4(ps, seed, nonces)
 	if L == 5 {
-		PolyDeriveUniformLeGamma1(&v[L-1], seed, nonce+4)
+		polyDeriveUniformLeGamma1(&v[L-1], seed, nonce+4, st)
 	} else if L > 5 || L < 2 {
 		panic("VecLDeriveUniformLeGamma1 does not support that L")
 	}
 }
 
 // Sample p uniformly with coefficients of norm less than γ₁ using the
-// given seed and nonce.
+// given seed and nonce.  This is ExpandMask of the specification.
 //
 // p will not be normalized, but have coefficients in the
 // interval (q-γ₁,q+γ₁).
+//
+// The seed is secret, so this function is on the secret-dependent path
+// of signing.  Rejection sampling is required by the round 2 specification
+// (unpacking the stream directly with UnpackLeGamma1 would change the
+// signatures), but the accepted coefficients do not depend on how the
+// rejected candidates compare to the bound: the comparison is done without
+// branching, and only the total number of candidates read leaks, which is
+// independent of the output.
 func PolyDeriveUniformLeGamma1(p *common.Poly, seed *[48]byte, nonce uint16) {
+	var st signState
+	polyDeriveUniformLeGamma1(p, seed, nonce, &st)
+}
+
+// Like PolyDeriveUniformLeGamma1, but uses the scratch space of st.
+func polyDeriveUniformLeGamma1(p *common.Poly, seed *[48]byte, nonce uint16,
+	st *signState) {
 	// Assumes γ₁ is less than 2²⁰.
 	var length, i int
 
@@ -344,13 +365,14 @@ func PolyDeriveUniformLeGamma1(p *common.Poly, seed *[48]byte, nonce uint16) {
 			t2 := ((uint32(buf[j+2]) >> 4) | (uint32(buf[j+3]) << 4) |
 				(uint32(buf[j+4]) << 12))
 
-			if t1 <= 2*common.Gamma1-2 {
-				p[i] = common.Q + common.Gamma1 - 1 - t1
-				i++
-			}
-			if t2 <= 2*common.Gamma1-2 && i < common.N {
+			// Write the candidate and only move on if it is less than
+			// or equal to 2γ₁-2, which fits in 20 bits, so that the
+			// subtraction underflows exactly when t > 2γ₁-2.
+			p[i] = common.Q + common.Gamma1 - 1 - t1
+			i += int(1 ^ ((2*common.Gamma1 - 2 - t1) >> 31))
+			if i < common.N {
 				p[i] = common.Q + common.Gamma1 - 1 - t2
-				i++
+				i += int(1 ^ ((2*common.Gamma1 - 2 - t2) >> 31))
 			}
 		}
 	}
@@ -364,14 +386,15 @@ func PolyDeriveUniformLeGamma1(p *common.Poly, seed *[48]byte, nonce uint16) {
 		}
 	} else {
 		length = 136
-		var iv [48 + 2]byte // 48 byte seed + uint16 nonce
-		bufOffset := 0      // where to put the next block
+		iv := st.iv[:] // 48 byte seed + uint16 nonce
+		bufOffset := 0 // where to put the next block
 
-		h := sha3.NewShake256()
+		h := &st.h
+		*h = sha3.NewShake256()
 		copy(iv[:48], seed[:])
 		iv[48] = uint8(nonce)
 		iv[49] = uint8(nonce >> 8)
-		_, _ = h.Write(iv[:])
+		_, _ = h.Write(iv)
 
 		for i < common.N {
 			_, _ = h.Read(buf[bufOffset : bufOffset+136])
@@ -517,15 +540,23 @@ PermuteLoop:
 //
 // The polynomial p will be normalized.
 func PolyDeriveUniformB60(p *common.Poly, seed *[48]byte, w1 *VecK) {
-	var w1Packed [common.PolyLe16Size * K]byte
-	var buf [136]byte // SHAKE-256 rate is 136
+	var st signState
+	polyDeriveUniformB60(p, seed, w1, &st)
+}
+
+// Like PolyDeriveUniformB60, but uses the scratch space of st.
+func polyDeriveUniformB60(p *common.Poly, seed *[48]byte, w1 *VecK,
+	st *signState) {
+	w1Packed := st.w1Packed[:]
+	buf := st.buf[:]
+	h := &st.h
+	*h = sha3.NewShake256()
 
-	w1.PackLe16(w1Packed[:])
+	w1.PackLe16(w1Packed)
 
-	h := sha3.NewShake256()
 	_, _ = h.Write(seed[:])
-	_, _ = h.Write(w1Packed[:])
-	_, _ = h.Read(buf[:])
+	_, _ = h.Write(w1Packed)
+	_, _ = h.Read(buf)
 
 	// Essentially we generate a sequence of 60 ones or minus ones,
 	// prepend 196 zeroes and shuffle the concatenation using the
@@ -540,8 +571,8 @@ func PolyDeriveUniformB60(p *common.Poly, seed *[48]byte, w1 *VecK) {
 		// Find location of where to move the new coefficient to using
 		// rejection sampling.
 		for {
-			if bufOff >= 136 {
-				_, _ = h.Read(buf[:])
+			if bufOff >= len(buf) {
+				_, _ = h.Read(buf)
 				bufOff = 0
 			}
 
@@ -18,4 +18,8 @@ const (
 	DoubleEtaBits  = {{ .DoubleEtaBits }}
 	Beta           = {{ .Beta }}
 	Omega          = {{ .Omega }}
+
+	// Hex-encoded SHAKE-256 digest of the signature computed by the
+	// self-test, see selfTest.
+	SelfTestDigest = "{{ .SelfTestDigest }}"
 )
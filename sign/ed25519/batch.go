@@ -0,0 +1,119 @@
+package ed25519
+
+import (
+	cryptoRand "crypto/rand"
+	"crypto/sha512"
+	"io"
+)
+
+// batchCoefSize is the size, in bytes, of the random coefficients used by
+// VerifyBatchSameKey, which bound the probability that an invalid batch
+// is accepted by 2^-128.
+const batchCoefSize = 16
+
+// batchOptions are the semantics of VerifyBatchSameKey: the cofactored
+// equation, with canonical encodings of points and scalars.
+var batchOptions = VerifyOptions{Cofactored: true, RequireCanonicalS: true}
+
+// VerifyBatchSameKey verifies the signatures sigs[i] of the messages
+// msgs[i] under the single public key pub. It returns whether all the
+// signatures are valid, along with the result for each of them.
+//
+// The public key is decoded once, and the signatures are checked together
+// with a random linear combination of their verification equations, which
+// is faster than verifying them one by one. Only if the batch fails, the
+// signatures are verified one by one to find the invalid ones.
+//
+// The batch uses the cofactored equation, and so does the one-by-one
+// verification, so the result for a signature does not depend on the other
+// signatures of the batch. It agrees with Verify on honestly generated
+// signatures, but accepts signatures crafted with small-order components
+// that Verify rejects, as VerifyWithOptions does with batchOptions.
+//
+// It returns false and nil if msgs and sigs have different lengths.
+func VerifyBatchSameKey(pub PublicKey, msgs, sigs [][]byte) (bool, []bool) {
+	if len(msgs) != len(sigs) {
+		return false, nil
+	}
+
+	valid := make([]bool, len(msgs))
+	if len(pub) != PublicKeySize {
+		return false, valid
+	}
+	tab := new(negKeyTable)
+	if ok := tab.fromBytes(pub, true); !ok {
+		return false, valid
+	}
+
+	// The sum of the z[i]*S[i] and z[i]*k[i] for random z[i].
+	var sumS, sumK, z, zero [paramB]byte
+	var sumR, negR pointR1
+	var tabR negKeyTable
+	var addR pointR2
+	sumR.SetIdentity()
+
+	H := sha512.New()
+	ok := true
+	for i := range sigs {
+		sig := sigs[i]
+		if len(sig) != SignatureSize || !isLessThanOrder(sig[paramB:]) {
+			ok = false
+			continue
+		}
+		R, S := sig[:paramB], sig[paramB:]
+		if !negR.fromBytes(R, true) {
+			ok = false
+			continue
+		}
+
+		H.Reset()
+		_, _ = H.Write(R)
+		_, _ = H.Write(pub)
+		_, _ = H.Write(msgs[i])
+		hRAM := H.Sum(nil)
+		reduceModOrder(hRAM, true)
+
+		if _, err := io.ReadFull(cryptoRand.Reader, z[:batchCoefSize]); err != nil {
+			return verifyEach(tab, pub, msgs, sigs, valid)
+		}
+		calculateS(sumS[:], sumS[:], z[:], S)
+		calculateS(sumK[:], sumK[:], z[:], hRAM[:paramB])
+
+		// Adds [z[i]](-R[i]) to sumR.
+		var Q pointR1
+		negR.neg()
+		negR.oddMultiples(tabR[:])
+		Q.doubleMultTable(&tabR, zero[:], z[:])
+		addR.fromR1(&Q)
+		sumR.add(&addR)
+
+		valid[i] = true
+	}
+
+	// Checks that [8]([sum z[i]*S[i]]B - [sum z[i]*k[i]]A - sum [z[i]]R[i])
+	// is the identity point.
+	var Q, id pointR1
+	Q.doubleMultTable(tab, sumS[:], sumK[:])
+	addR.fromR1(&sumR)
+	Q.add(&addR)
+	Q.double()
+	Q.double()
+	Q.double()
+	id.SetIdentity()
+	if !Q.isEqual(&id) {
+		return verifyEach(tab, pub, msgs, sigs, valid)
+	}
+
+	return ok, valid
+}
+
+// verifyEach sets valid[i] to whether sigs[i] is a valid signature of
+// msgs[i] under batchOptions, and returns whether all of them are valid.
+func verifyEach(tab *negKeyTable, pub PublicKey, msgs, sigs [][]byte, valid []bool) (bool, []bool) {
+	ok := true
+	for i := range sigs {
+		valid[i] = verifyWithTable(tab, pub, msgs[i], sigs[i], []byte(""), false, batchOptions)
+		ok = ok && valid[i]
+	}
+	return ok, valid
+}
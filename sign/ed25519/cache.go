@@ -0,0 +1,96 @@
+package ed25519
+
+import (
+	"container/list"
+	"sync"
+)
+
+// VerifierCache verifies signatures as Verify does, but keeps the decoded
+// form of the most recently used public keys, which saves decoding the key
+// and precomputing its multiples when keys recur.
+//
+// The cache holds at most a fixed number of keys, evicting the least
+// recently used one, and is safe for concurrent use by multiple goroutines.
+type VerifierCache struct {
+	mu   sync.Mutex
+	size int
+	lru  *list.List // of *cachedKey, most recently used first.
+	keys map[[PublicKeySize]byte]*list.Element
+}
+
+type cachedKey struct {
+	public [PublicKeySize]byte
+	tab    negKeyTable
+}
+
+// NewVerifierCache returns a VerifierCache holding at most size public keys.
+// It panics if size is not positive.
+func NewVerifierCache(size int) *VerifierCache {
+	if size <= 0 {
+		panic("ed25519: non-positive cache size")
+	}
+	return &VerifierCache{
+		size: size,
+		lru:  list.New(),
+		keys: make(map[[PublicKeySize]byte]*list.Element, size),
+	}
+}
+
+// Len returns the number of public keys in the cache.
+func (c *VerifierCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Verify returns true if the signature is valid. It has the same semantics
+// as Verify, but looks up the public key in the cache before decoding it.
+// Public keys that cannot be decoded are not cached.
+func (c *VerifierCache) Verify(public PublicKey, message, signature []byte) bool {
+	if len(public) != PublicKeySize || len(signature) != SignatureSize {
+		return false
+	}
+	tab := c.lookup(public)
+	if tab == nil {
+		return false
+	}
+	return verifyWithTable(tab, public, message, signature, []byte(""), false, rfc8032)
+}
+
+// lookup returns the table of the public key, decoding and caching it on a
+// miss. Returns nil if public is not a valid point encoding.
+func (c *VerifierCache) lookup(public PublicKey) *negKeyTable {
+	var key [PublicKeySize]byte
+	copy(key[:], public)
+
+	c.mu.Lock()
+	if e, ok := c.keys[key]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		// Tables are never modified once cached, so it is safe to read it
+		// without holding the lock.
+		return &e.Value.(*cachedKey).tab
+	}
+	c.mu.Unlock()
+
+	// Decode without holding the lock, so that misses do not serialize.
+	k := &cachedKey{public: key}
+	if ok := k.tab.fromBytes(public, true); !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.keys[key]; ok {
+		// Another goroutine cached the same key meanwhile.
+		c.lru.MoveToFront(e)
+		return &e.Value.(*cachedKey).tab
+	}
+	c.keys[key] = c.lru.PushFront(k)
+	if c.lru.Len() > c.size {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.keys, last.Value.(*cachedKey).public)
+	}
+	return &k.tab
+}
@@ -0,0 +1,79 @@
+package ed25519
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+)
+
+const (
+	// KeyIDSize is the size, in bytes, of the key ids returned by KeyID.
+	KeyIDSize = 8
+	// DetachedSignatureSize is the size, in bytes, of marshaled
+	// DetachedSignatures.
+	DetachedSignatureSize = KeyIDSize + SignatureSize
+)
+
+// DetachedSignature is an Ed25519 signature bundled with the id of the key
+// that verifies it, as in the signature files of signing tools like signify
+// or minisign. The key id lets verifiers pick the right key when several
+// are possible.
+//
+// It is marshaled as the key id followed by the signature.
+type DetachedSignature struct {
+	KeyID     [KeyIDSize]byte
+	Signature [SignatureSize]byte
+}
+
+// KeyID returns a short identifier of pub, namely the first KeyIDSize
+// bytes of SHA-512(pub). It is not a commitment to the key: only the
+// signature binds the message to pub.
+func (pub PublicKey) KeyID() (id [KeyIDSize]byte) {
+	h := sha512.Sum512(pub)
+	copy(id[:], h[:])
+	return
+}
+
+// SignDetached signs the message with privateKey as Sign does, and returns
+// the signature along with the id of the public key.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func SignDetached(privateKey PrivateKey, message []byte) *DetachedSignature {
+	sig := new(DetachedSignature)
+	signAll(sig.Signature[:], privateKey, message, []byte(""), false)
+	sig.KeyID = PublicKey(privateKey[SeedSize:]).KeyID()
+	return sig
+}
+
+// VerifyDetached returns true if blob is a marshaled DetachedSignature of
+// message whose key id is that of public, and whose signature is valid
+// for public as checked by Verify.
+func VerifyDetached(public PublicKey, message, blob []byte) bool {
+	var sig DetachedSignature
+	if err := sig.UnmarshalBinary(blob); err != nil {
+		return false
+	}
+	id := public.KeyID()
+	if subtle.ConstantTimeCompare(sig.KeyID[:], id[:]) != 1 {
+		return false
+	}
+	return Verify(public, message, sig.Signature[:])
+}
+
+// MarshalBinary returns the key id followed by the signature.
+func (sig *DetachedSignature) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, 0, DetachedSignatureSize)
+	data = append(data, sig.KeyID[:]...)
+	data = append(data, sig.Signature[:]...)
+	return data, nil
+}
+
+// UnmarshalBinary sets sig to the DetachedSignature encoded in data. It
+// returns an error if len(data) is not DetachedSignatureSize.
+func (sig *DetachedSignature) UnmarshalBinary(data []byte) error {
+	if len(data) != DetachedSignatureSize {
+		return errors.New("ed25519: bad detached signature length")
+	}
+	copy(sig.KeyID[:], data[:KeyIDSize])
+	copy(sig.Signature[:], data[KeyIDSize:])
+	return nil
+}
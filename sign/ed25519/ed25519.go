@@ -28,6 +28,11 @@
 // operations with the same key more efficient. This package refers to the
 // RFC-8032 private key as the “seed”.
 //
+// Self-test
+//
+// When built with the selftest tag, the package checks the signature of a
+// test vector of RFC-8032 at initialization, and panics if it mismatches.
+//
 // References
 //
 //  - RFC-8032: https://rfc-editor.org/rfc/rfc8032.txt
@@ -42,11 +47,12 @@ import (
 	"crypto/sha512"
 	"crypto/subtle"
 	"errors"
-	"fmt"
+	"hash"
 	"io"
 	"strconv"
 
 	"github.com/cloudflare/circl/sign"
+	"golang.org/x/crypto/hkdf"
 )
 
 const (
@@ -66,6 +72,20 @@ const (
 	paramB = 256 / 8 // Size of keys in bytes.
 )
 
+// ErrContextTooLong is the error returned, or the value panicked with, when
+// a context string is longer than ContextMaxSize.
+var ErrContextTooLong = errors.New("ed25519: context too long")
+
+// validateContext returns ErrContextTooLong if ctx is longer than
+// ContextMaxSize. The context string of Ed25519ph and Ed25519ctx is encoded
+// with a one-byte length prefix, so longer ones cannot be represented.
+func validateContext(ctx []byte) error {
+	if len(ctx) > ContextMaxSize {
+		return ErrContextTooLong
+	}
+	return nil
+}
+
 // SignerOptions implements crypto.SignerOpts and augments with parameters
 // that are specific to the Ed25519 signature schemes.
 type SignerOptions struct {
@@ -92,6 +112,9 @@ const (
 )
 
 // PrivateKey is the type of Ed25519 private keys. It implements crypto.Signer.
+//
+// As a PrivateKey is a byte slice, slicing or assigning it aliases the key
+// material. Use Bytes to obtain an independent copy.
 type PrivateKey []byte
 
 // Equal reports whether priv and x have the same value.
@@ -116,6 +139,44 @@ func (priv PrivateKey) Seed() []byte {
 	return seed
 }
 
+// ExpandedSecret returns the secret values derived from the seed of priv, as
+// defined in RFC 8032 (Section 5.1.5), for use in hierarchical key derivation
+// schemes such as SLIP-0010. The layout is
+//
+//   s ‖ prefix = SHA-512(seed),
+//
+// where s is the 32-byte clamped scalar in little-endian order, i.e., with
+// the three least significant bits cleared, the most significant bit
+// cleared, and the second-most significant bit set, and prefix is the
+// remaining 32 bytes used to derive the nonces of signatures. The scalar is
+// not reduced modulo the group order.
+//
+// Warning: the expanded secret is as sensitive as the seed itself. Anyone
+// knowing it can create signatures on behalf of priv, so it must be
+// protected accordingly.
+func (priv PrivateKey) ExpandedSecret() [64]byte {
+	k := sha512.Sum512(priv[:SeedSize])
+	clamp(k[:])
+	return k
+}
+
+// NoncePrefix returns the prefix of the expanded secret of priv, that is,
+// the second half of SHA-512(seed), from which the nonces of signatures are
+// derived. Along with the scalar in the first half of ExpandedSecret, it
+// lets other schemes, such as XEdDSA, or SignWithScalar produce the same
+// signatures as Sign.
+//
+// Warning: the prefix is secret. Anyone knowing it and a signature can
+// recover the nonce of other signatures of the same message, and so the
+// private key. Using it in another scheme must not make two distinct
+// signatures share a nonce, so it must not be shared across schemes unless
+// they derive nonces with domain separation.
+func (priv PrivateKey) NoncePrefix() (prefix [32]byte) {
+	k := sha512.Sum512(priv[:SeedSize])
+	copy(prefix[:], k[paramB:])
+	return
+}
+
 func (priv PrivateKey) Scheme() sign.Scheme { return Scheme }
 
 func (pub PublicKey) Scheme() sign.Scheme { return Scheme }
@@ -132,12 +193,34 @@ func (pub PublicKey) MarshalBinary() (data []byte, err error) {
 	return publicKey, nil
 }
 
+// Bytes returns a copy of the private key, that is, the seed followed by
+// the public key. Modifying the returned slice does not affect priv.
+func (priv PrivateKey) Bytes() []byte {
+	b := make([]byte, len(priv))
+	copy(b, priv)
+	return b
+}
+
+// Bytes returns a copy of the public key. Modifying the returned slice does
+// not affect pub.
+func (pub PublicKey) Bytes() []byte {
+	b := make([]byte, len(pub))
+	copy(b, pub)
+	return b
+}
+
 // Equal reports whether pub and x have the same value.
 func (pub PublicKey) Equal(x crypto.PublicKey) bool {
 	xx, ok := x.(PublicKey)
 	return ok && bytes.Equal(pub, xx)
 }
 
+// Verify returns true if signature is a valid signature of message by pub.
+// The signature variant is selected by opts as in VerifyAny.
+func (pub PublicKey) Verify(message, signature []byte, opts crypto.SignerOpts) bool {
+	return VerifyAny(pub, message, signature, opts)
+}
+
 // Sign creates a signature of a message with priv key.
 // This function is compatible with crypto.ed25519 and also supports the
 // three signature variants defined in RFC-8032, namely Ed25519 (or pure
@@ -147,22 +230,45 @@ func (pub PublicKey) Equal(x crypto.PublicKey) bool {
 // opts.
 // The opts.HashFunc() must return SHA512 to specify the Ed25519Ph variant.
 // This can be achieved by passing crypto.SHA512 as the value for opts.
-// Use a SignerOptions struct (defined in this package) to pass a context
-// string for signing.
+// Any other hash function is rejected, as Ed25519Ph is only defined with
+// SHA-512.
+// As in crypto.Signer, if opts is not a SignerOptions, the message passed
+// with crypto.SHA512 is its SHA-512 digest, which must be 64 bytes long.
+// With a SignerOptions struct (defined in this package), the message is
+// hashed by Sign as in SignPh, and a context string can be passed for
+// signing.
+//
+// Earlier versions hashed the message passed with a plain crypto.SHA512 as
+// well. Callers that pass the message itself must now use
+// SignerOptions{Hash: crypto.SHA512, Scheme: ED25519Ph} instead.
 func (priv PrivateKey) Sign(
 	rand io.Reader,
 	message []byte,
 	opts crypto.SignerOpts) (signature []byte, err error) {
 	var ctx string
 	var scheme SchemeID
+	var isDigest bool
 	if o, ok := opts.(SignerOptions); ok {
 		ctx = o.Context
 		scheme = o.Scheme
+	} else if opts.HashFunc() == crypto.SHA512 {
+		scheme = ED25519Ph
+		isDigest = true
+	}
+	if err := validateContext([]byte(ctx)); err != nil {
+		return nil, err
 	}
 
 	switch true {
 	case scheme == ED25519 && opts.HashFunc() == crypto.Hash(0):
 		return Sign(priv, message), nil
+	case scheme == ED25519Ph && opts.HashFunc() == crypto.SHA512 && isDigest:
+		if len(message) != sha512.Size {
+			return nil, errors.New("ed25519: bad Ed25519ph SHA-512 digest length")
+		}
+		signature = make([]byte, SignatureSize)
+		signPHM(signature, priv, message, []byte(""), true)
+		return signature, nil
 	case scheme == ED25519Ph && opts.HashFunc() == crypto.SHA512:
 		return SignPh(priv, message, ctx), nil
 	case scheme == ED25519Ctx && opts.HashFunc() == crypto.Hash(0) && len(ctx) > 0:
@@ -201,6 +307,38 @@ func NewKeyFromSeed(seed []byte) PrivateKey {
 	return privateKey
 }
 
+// NewKeyFromIKM derives a private key from the input key material ikm, for
+// example, the shared secret of a KEM. The seed of the private key is
+//
+//   seed = HKDF-SHA512(ikm, salt, info, 32),
+//
+// that is, the first SeedSize bytes output by HKDF (RFC 5869) with SHA-512,
+// using salt for the extraction and info for the expansion. Both salt and
+// info may be empty. The ikm must have enough entropy to serve as a key.
+func NewKeyFromIKM(ikm, salt, info []byte) PrivateKey {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(hkdf.New(sha512.New, ikm, salt, info), seed); err != nil {
+		panic(err)
+	}
+	return NewKeyFromSeed(seed)
+}
+
+// PublicFromScalar calculates the public key [scalar]B, where scalar is a
+// 32-byte little-endian integer and B is the generator point.
+// The scalar is used as given: it is neither derived from a seed with
+// SHA-512 nor clamped. As this bypasses the seed expansion of RFC-8032,
+// there is no seed corresponding to the resulting public key, and thus it
+// cannot be used with the signing functions of this package.
+func PublicFromScalar(scalar [32]byte) PublicKey {
+	var P pointR1
+	k := scalar
+	reduceModOrder(k[:], false)
+	P.fixedMult(k[:])
+	publicKey := make(PublicKey, PublicKeySize)
+	_ = P.ToBytes(publicKey)
+	return publicKey
+}
+
 func newKeyFromSeed(privateKey, seed []byte) {
 	if l := len(seed); l != SeedSize {
 		panic("ed25519: bad seed length: " + strconv.Itoa(l))
@@ -219,40 +357,39 @@ func signAll(signature []byte, privateKey PrivateKey, message, ctx []byte, preHa
 		panic("ed25519: bad private key length: " + strconv.Itoa(l))
 	}
 
-	H := sha512.New()
-	var PHM []byte
-
+	PHM := message
 	if preHash {
-		_, _ = H.Write(message)
-		PHM = H.Sum(nil)
-		H.Reset()
-	} else {
-		PHM = message
+		h := sha512.Sum512(message)
+		PHM = h[:]
+	}
+	signPHM(signature, privateKey, PHM, ctx, preHash)
+}
+
+// signPHM is like signAll, but takes PH(M), which for Ed25519ph is the
+// SHA-512 digest of the message, and the message itself otherwise.
+func signPHM(signature []byte, privateKey PrivateKey, PHM, ctx []byte, preHash bool) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
 	}
 
 	// 1.  Hash the 32-byte private key using SHA-512.
+	H := sha512.New()
 	_, _ = H.Write(privateKey[:SeedSize])
 	h := H.Sum(nil)
 	clamp(h[:])
 	prefix, s := h[paramB:], h[:paramB]
 
-	// 2.  Compute SHA-512(dom2(F, C) || prefix || PH(M))
-	H.Reset()
-
-	writeDom(H, ctx, preHash)
-
-	_, _ = H.Write(prefix)
-	_, _ = H.Write(PHM)
-	r := H.Sum(nil)
-	reduceModOrder(r[:], true)
+	signExpanded(signature, H, prefix, s, privateKey[SeedSize:], PHM, ctx, preHash)
+}
 
+// signExpanded performs the steps 2 to 6 of signing, given the halves prefix
+// and s of the expanded private key, and the public key.
+func signExpanded(signature []byte, H hash.Hash, prefix, s, public, PHM, ctx []byte, preHash bool) {
+	// 2.  Compute SHA-512(dom2(F, C) || prefix || PH(M))
 	// 3.  Compute the point [r]B.
-	var P pointR1
-	P.fixedMult(r[:paramB])
+	r := (&[paramB]byte{})[:]
 	R := (&[paramB]byte{})[:]
-	if err := P.ToBytes(R); err != nil {
-		panic(err)
-	}
+	deriveNonce(r, R, H, prefix, PHM, ctx, preHash)
 
 	// 4.  Compute SHA512(dom2(F, C) || R || A || PH(M)).
 	H.Reset()
@@ -260,7 +397,7 @@ func signAll(signature []byte, privateKey PrivateKey, message, ctx []byte, preHa
 	writeDom(H, ctx, preHash)
 
 	_, _ = H.Write(R)
-	_, _ = H.Write(privateKey[SeedSize:])
+	_, _ = H.Write(public)
 	_, _ = H.Write(PHM)
 	hRAM := H.Sum(nil)
 
@@ -268,13 +405,33 @@ func signAll(signature []byte, privateKey PrivateKey, message, ctx []byte, preHa
 
 	// 5.  Compute S = (r + k * s) mod order.
 	S := (&[paramB]byte{})[:]
-	calculateS(S, r[:paramB], hRAM[:paramB], s)
+	calculateS(S, r, hRAM[:paramB], s)
 
 	// 6.  The signature is the concatenation of R and S.
 	copy(signature[:paramB], R[:])
 	copy(signature[paramB:], S[:])
 }
 
+// deriveNonce sets r to the nonce SHA-512(dom2(F, C) || prefix || PH(M))
+// reduced modulo the order, and R to the encoding of the commitment [r]B.
+func deriveNonce(r, R []byte, H hash.Hash, prefix, PHM, ctx []byte, preHash bool) {
+	H.Reset()
+
+	writeDom(H, ctx, preHash)
+
+	_, _ = H.Write(prefix)
+	_, _ = H.Write(PHM)
+	h := H.Sum(nil)
+	reduceModOrder(h[:], true)
+	copy(r, h[:paramB])
+
+	var P pointR1
+	P.fixedMult(r)
+	if err := P.ToBytes(R); err != nil {
+		panic(err)
+	}
+}
+
 // Sign signs the message with privateKey and returns a signature.
 // This function supports the signature variant defined in RFC-8032: Ed25519,
 // also known as the pure version of EdDSA.
@@ -285,16 +442,86 @@ func Sign(privateKey PrivateKey, message []byte) []byte {
 	return signature
 }
 
+// SignFromSeed derives the private key of seed as NewKeyFromSeed does, and
+// returns the signature of the message along with the public key, in a
+// single call for signers that only keep seeds. If ph is true, the message
+// is signed with Ed25519ph and an empty context as SignPh does, otherwise
+// with Ed25519 as Sign does.
+// It returns an error if len(seed) is not SeedSize.
+func SignFromSeed(seed, message []byte, ph bool) (sig []byte, pub PublicKey, err error) {
+	if l := len(seed); l != SeedSize {
+		return nil, nil, errors.New("ed25519: bad seed length: " + strconv.Itoa(l))
+	}
+
+	privateKey := NewKeyFromSeed(seed)
+	sig = make([]byte, SignatureSize)
+	signAll(sig, privateKey, message, []byte(""), ph)
+	pub = make(PublicKey, PublicKeySize)
+	copy(pub, privateKey[SeedSize:])
+	return sig, pub, nil
+}
+
+// SignBatch signs each of msgs with privateKey and returns the signatures in
+// the same order. The signatures are the same as those returned by Sign, and
+// the private key is expanded only once. This saves allocations but not time,
+// which is dominated by the scalar multiplication of each signature.
+// It returns an error if len(privateKey) is not PrivateKeySize.
+func SignBatch(privateKey PrivateKey, msgs [][]byte) ([][]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, errors.New("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+
+	h := sha512.Sum512(privateKey[:SeedSize])
+	clamp(h[:])
+	prefix, s := h[paramB:], h[:paramB]
+
+	H := sha512.New()
+	buf := make([]byte, len(msgs)*SignatureSize)
+	signatures := make([][]byte, len(msgs))
+	for i := range msgs {
+		sig := buf[i*SignatureSize : (i+1)*SignatureSize : (i+1)*SignatureSize]
+		signExpanded(sig, H, prefix, s, privateKey[SeedSize:], msgs[i], []byte(""), false)
+		signatures[i] = sig
+	}
+
+	return signatures, nil
+}
+
+// SignWithScalar signs the message as Sign does, but using the given scalar
+// and nonce prefix instead of expanding them from a seed. The scalar is a
+// 32-byte little-endian integer, and the public key of the signature is
+// PublicFromScalar(scalar). Passing the halves of ExpandedSecret gives the
+// same signature as Sign.
+//
+// Warning: the nonce of a signature is derived from prefix and the message
+// only, so the caller is responsible for prefix being secret, and unique to
+// the scalar. Signing two different messages with the same nonce under
+// different scalars, or the nonce becoming known, reveals the scalar.
+// It returns an error if the scalar is zero modulo the group order.
+func SignWithScalar(scalar, prefix [32]byte, message []byte) ([]byte, error) {
+	k := scalar
+	reduceModOrder(k[:], false)
+	if k == [paramB]byte{} {
+		return nil, errors.New("ed25519: zero scalar")
+	}
+
+	public := PublicFromScalar(scalar)
+	signature := make([]byte, SignatureSize)
+	signExpanded(signature, sha512.New(), prefix[:], k[:], public, message, []byte(""), false)
+	return signature, nil
+}
+
 // SignPh creates a signature of a message with private key and context.
 // This function supports the signature variant defined in RFC-8032: Ed25519ph,
 // meaning it internally hashes the message using SHA-512, and optionally
 // accepts a context string.
 // It will panic if len(privateKey) is not PrivateKeySize.
 // Context could be passed to this function, which length should be no more than
-// ContextMaxSize=255. It can be empty.
+// ContextMaxSize=255, otherwise it panics with ErrContextTooLong. It can be
+// empty.
 func SignPh(privateKey PrivateKey, message []byte, ctx string) []byte {
-	if len(ctx) > ContextMaxSize {
-		panic(fmt.Errorf("ed25519: bad context length: %v", len(ctx)))
+	if err := validateContext([]byte(ctx)); err != nil {
+		panic(err)
 	}
 
 	signature := make([]byte, SignatureSize)
@@ -307,10 +534,14 @@ func SignPh(privateKey PrivateKey, message []byte, ctx string) []byte {
 // meaning it accepts a non-empty context string.
 // It will panic if len(privateKey) is not PrivateKeySize.
 // Context must be passed to this function, which length should be no more than
-// ContextMaxSize=255 and cannot be empty.
+// ContextMaxSize=255, otherwise it panics with ErrContextTooLong, and cannot
+// be empty, which would make it the same as Ed25519.
 func SignWithCtx(privateKey PrivateKey, message []byte, ctx string) []byte {
-	if len(ctx) == 0 || len(ctx) > ContextMaxSize {
-		panic(fmt.Errorf("ed25519: bad context length: %v > %v", len(ctx), ContextMaxSize))
+	if len(ctx) == 0 {
+		panic(errors.New("ed25519: empty context"))
+	}
+	if err := validateContext([]byte(ctx)); err != nil {
+		panic(err)
 	}
 
 	signature := make([]byte, SignatureSize)
@@ -318,29 +549,40 @@ func SignWithCtx(privateKey PrivateKey, message []byte, ctx string) []byte {
 	return signature
 }
 
-func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool {
-	if len(public) != PublicKeySize ||
-		len(signature) != SignatureSize ||
-		!isLessThanOrder(signature[paramB:]) {
-		return false
+func verify(public PublicKey, message, signature, ctx []byte, preHash bool, opts VerifyOptions) bool {
+	PHM := message
+	if preHash {
+		h := sha512.Sum512(message)
+		PHM = h[:]
 	}
+	return verifyWithTable(nil, public, PHM, signature, ctx, preHash, opts)
+}
 
-	var P pointR1
-	if ok := P.FromBytes(public); !ok {
+// verifyWithTable is like verify, but takes PH(M) instead of the message,
+// as signPHM does, and if tab is not nil, it is used as the table of odd
+// multiples of -A instead of decoding the public key.
+func verifyWithTable(tab *negKeyTable, public PublicKey, PHM, signature, ctx []byte, preHash bool, opts VerifyOptions) bool {
+	if len(public) != PublicKeySize || len(signature) != SignatureSize {
 		return false
 	}
 
-	H := sha512.New()
-	var PHM []byte
+	S := signature[paramB:]
+	if opts.RequireCanonicalS {
+		if !isLessThanOrder(S) {
+			return false
+		}
+	} else if S[paramB-1]&0xE0 != 0 {
+		return false
+	}
 
-	if preHash {
-		_, _ = H.Write(message)
-		PHM = H.Sum(nil)
-		H.Reset()
-	} else {
-		PHM = message
+	if tab == nil {
+		tab = new(negKeyTable)
+		if ok := tab.fromBytes(public, !opts.AllowNonCanonical); !ok {
+			return false
+		}
 	}
 
+	H := sha512.New()
 	R := signature[:paramB]
 
 	writeDom(H, ctx, preHash)
@@ -352,11 +594,33 @@ func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool
 	reduceModOrder(hRAM[:], true)
 
 	var Q pointR1
-	encR := (&[paramB]byte{})[:]
-	P.neg()
-	Q.doubleMult(&P, signature[paramB:], hRAM[:paramB])
-	_ = Q.ToBytes(encR)
-	return bytes.Equal(R, encR)
+	Q.doubleMultTable(tab, S, hRAM[:paramB])
+
+	if !opts.Cofactored && !opts.AllowNonCanonical {
+		encR := (&[paramB]byte{})[:]
+		_ = Q.ToBytes(encR)
+		return bytes.Equal(R, encR)
+	}
+
+	var pointR pointR1
+	if ok := pointR.fromBytes(R, !opts.AllowNonCanonical); !ok {
+		return false
+	}
+	if !opts.Cofactored {
+		return Q.isEqual(&pointR)
+	}
+
+	// Checks that [8]([S]B - [k]A - R) is the identity point.
+	var negR pointR2
+	pointR.neg()
+	negR.fromR1(&pointR)
+	Q.add(&negR)
+	Q.double()
+	Q.double()
+	Q.double()
+	var id pointR1
+	id.SetIdentity()
+	return Q.isEqual(&id)
 }
 
 // VerifyAny returns true if the signature is valid. Failure cases are invalid
@@ -367,18 +631,34 @@ func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool
 // variant. This can be achieved by passing crypto.Hash(0) as the value for opts.
 // The opts.HashFunc() must return SHA512 to specify the Ed25519Ph variant.
 // This can be achieved by passing crypto.SHA512 as the value for opts.
+// Any other hash function is rejected, as Ed25519Ph is only defined with
+// SHA-512.
+// As in (PrivateKey).Sign, if opts is not a SignerOptions, the message
+// passed with crypto.SHA512 is its SHA-512 digest. Earlier versions hashed
+// it instead, which now requires SignerOptions{Hash: crypto.SHA512,
+// Scheme: ED25519Ph}.
 // Use a SignerOptions struct to pass a context string for signing.
 func VerifyAny(public PublicKey, message, signature []byte, opts crypto.SignerOpts) bool {
 	var ctx string
 	var scheme SchemeID
+	var isDigest bool
 	if o, ok := opts.(SignerOptions); ok {
 		ctx = o.Context
 		scheme = o.Scheme
+	} else if opts.HashFunc() == crypto.SHA512 {
+		scheme = ED25519Ph
+		isDigest = true
+	}
+	if validateContext([]byte(ctx)) != nil {
+		return false
 	}
 
 	switch true {
 	case scheme == ED25519 && opts.HashFunc() == crypto.Hash(0):
 		return Verify(public, message, signature)
+	case scheme == ED25519Ph && opts.HashFunc() == crypto.SHA512 && isDigest:
+		return len(message) == sha512.Size &&
+			verifyWithTable(nil, public, message, signature, []byte(""), true, rfc8032)
 	case scheme == ED25519Ph && opts.HashFunc() == crypto.SHA512:
 		return VerifyPh(public, message, signature, ctx)
 	case scheme == ED25519Ctx && opts.HashFunc() == crypto.Hash(0) && len(ctx) > 0:
@@ -393,7 +673,32 @@ func VerifyAny(public PublicKey, message, signature []byte, opts crypto.SignerOp
 // This function supports the signature variant defined in RFC-8032: Ed25519,
 // also known as the pure version of EdDSA.
 func Verify(public PublicKey, message, signature []byte) bool {
-	return verify(public, message, signature, []byte(""), false)
+	return verify(public, message, signature, []byte(""), false, rfc8032)
+}
+
+// VerifyCanonicalizations returns the index of the first of candidates for
+// which the signature is valid, as checked by Verify, and true. It returns
+// -1 and false if none is. This helps interoperating with protocols that are
+// ambiguous about the signed message, for example, whether it ends with a
+// newline, by trying each of its possible forms. The public key is decoded
+// only once for all candidates.
+//
+// Accepting several messages weakens what a signature proves, so callers
+// should keep the candidates to the few forms the protocol allows.
+func VerifyCanonicalizations(public PublicKey, signature []byte, candidates [][]byte) (int, bool) {
+	if len(public) != PublicKeySize || len(signature) != SignatureSize {
+		return -1, false
+	}
+	var tab negKeyTable
+	if ok := tab.fromBytes(public, true); !ok {
+		return -1, false
+	}
+	for i, m := range candidates {
+		if verifyWithTable(&tab, public, m, signature, []byte(""), false, rfc8032) {
+			return i, true
+		}
+	}
+	return -1, false
 }
 
 // VerifyPh returns true if the signature is valid. Failure cases are invalid
@@ -403,7 +708,11 @@ func Verify(public PublicKey, message, signature []byte) bool {
 // Context could be passed to this function, which length should be no more than
 // 255. It can be empty.
 func VerifyPh(public PublicKey, message, signature []byte, ctx string) bool {
-	return verify(public, message, signature, []byte(ctx), true)
+	if validateContext([]byte(ctx)) != nil {
+		return false
+	}
+
+	return verify(public, message, signature, []byte(ctx), true, rfc8032)
 }
 
 // VerifyWithCtx returns true if the signature is valid. Failure cases are invalid
@@ -413,11 +722,68 @@ func VerifyPh(public PublicKey, message, signature []byte, ctx string) bool {
 // meaning it does not handle prehashed messages. Non-empty context string must be
 // provided, and must not be more than 255 of length.
 func VerifyWithCtx(public PublicKey, message, signature []byte, ctx string) bool {
-	if len(ctx) == 0 || len(ctx) > ContextMaxSize {
+	if len(ctx) == 0 || validateContext([]byte(ctx)) != nil {
 		return false
 	}
 
-	return verify(public, message, signature, []byte(ctx), false)
+	return verify(public, message, signature, []byte(ctx), false, rfc8032)
+}
+
+// VerifyOptions selects the checks performed by VerifyWithOptions.
+//
+// Implementations of Ed25519 disagree on which signatures are valid, see
+// "Taming the many EdDSAs" (https://eprint.iacr.org/2020/1244). RFC-8032
+// defines the cofactored equation [8][S]B = [8]R + [8][k]A as the check,
+// but allows the stricter cofactorless equation [S]B = R + [k]A, which is
+// what Verify implements. Both agree on honestly generated signatures, but
+// differ on signatures crafted with small-order components, which matters
+// for systems that need consensus among verifiers.
+type VerifyOptions struct {
+	// Cofactored selects the cofactored verification equation. Otherwise,
+	// the cofactorless equation is used.
+	Cofactored bool
+
+	// AllowNonCanonical accepts non-canonical encodings of the public key
+	// and of the R component of the signature, that is, encodings with the
+	// y-coordinate not reduced modulo p, or with x = 0 and the sign bit set.
+	AllowNonCanonical bool
+
+	// RequireCanonicalS enforces that S is less than the group order, as
+	// required by RFC-8032. Otherwise, S is only required to be less than
+	// 2^253, as older versions of libsodium did.
+	RequireCanonicalS bool
+}
+
+var (
+	rfc8032         = VerifyOptions{Cofactored: false, AllowNonCanonical: false, RequireCanonicalS: true}
+	zip215          = VerifyOptions{Cofactored: true, AllowNonCanonical: true, RequireCanonicalS: true}
+	libsodiumCompat = VerifyOptions{Cofactored: false, AllowNonCanonical: false, RequireCanonicalS: false}
+)
+
+// RFC8032 returns options that verify with the cofactorless equation, and
+// reject non-canonical encodings of points and scalars. They have the same
+// semantics as Verify.
+func RFC8032() VerifyOptions { return rfc8032 }
+
+// ZIP215 returns options that follow the validation rules defined in ZIP-215
+// (https://zips.z.cash/zip-0215): verify with the cofactored equation,
+// accept non-canonical encodings of points, and reject non-canonical
+// scalars.
+func ZIP215() VerifyOptions { return zip215 }
+
+// LibsodiumCompat returns options that verify with the cofactorless
+// equation, reject non-canonical encodings of points, and only check that
+// S < 2^253, as older versions of libsodium did. Note that libsodium also
+// rejects small-order points, which is not checked by this package.
+func LibsodiumCompat() VerifyOptions { return libsodiumCompat }
+
+// VerifyWithOptions returns true if the signature is valid under the
+// semantics selected by opts. Failure cases are invalid signature, or when
+// the public key cannot be decoded.
+// This function supports the signature variant defined in RFC-8032: Ed25519,
+// also known as the pure version of EdDSA.
+func VerifyWithOptions(public PublicKey, message, signature []byte, opts VerifyOptions) bool {
+	return verify(public, message, signature, []byte(""), false, opts)
 }
 
 func clamp(k []byte) {
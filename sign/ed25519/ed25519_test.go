@@ -96,8 +96,67 @@ func BenchmarkVerification(b *testing.B) {
 	}
 	message := []byte("Hello, world!")
 	signature := ed25519.Sign(priv, message)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		ed25519.Verify(pub, message, signature)
 	}
 }
+
+func BenchmarkVerificationCache(b *testing.B) {
+	// A workload of signatures under a recurring set of keys.
+	const numKeys = 1000
+	var pubs [numKeys]ed25519.PublicKey
+	var msgs, sigs [numKeys][]byte
+	for j := range pubs {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0], seed[1] = byte(j), byte(j>>8)
+		priv := ed25519.NewKeyFromSeed(seed)
+		pubs[j] = priv.Public().(ed25519.PublicKey)
+		msgs[j] = []byte{byte(j), byte(j >> 8)}
+		sigs[j] = ed25519.Sign(priv, msgs[j])
+	}
+
+	b.Run("Verify", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			j := i % numKeys
+			ed25519.Verify(pubs[j], msgs[j], sigs[j])
+		}
+	})
+	b.Run("VerifierCache", func(b *testing.B) {
+		cache := ed25519.NewVerifierCache(numKeys)
+		for j := range pubs {
+			cache.Verify(pubs[j], msgs[j], sigs[j])
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			j := i % numKeys
+			cache.Verify(pubs[j], msgs[j], sigs[j])
+		}
+	})
+}
+
+func BenchmarkVerificationBatch(b *testing.B) {
+	const batchSize = 64
+	var pubs [batchSize]ed25519.PublicKey
+	var msgs, sigs [batchSize][]byte
+	for j := range pubs {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(j)
+		priv := ed25519.NewKeyFromSeed(seed)
+		pubs[j] = priv.Public().(ed25519.PublicKey)
+		msgs[j] = []byte{byte(j)}
+		sigs[j] = ed25519.Sign(priv, msgs[j])
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range pubs {
+			if !ed25519.Verify(pubs[j], msgs[j], sigs[j]) {
+				b.Fatal("verification failed")
+			}
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/cloudflare/circl/internal/test"
@@ -106,6 +109,116 @@ func testSigner(t *testing.T, signer crypto.Signer, ops ed25519.SignerOptions) {
 	if got != want {
 		test.ReportError(t, got, want, ops)
 	}
+
+	got = pubSigner.Verify(msg, sig, ops)
+	if got != want {
+		test.ReportError(t, got, want, ops)
+	}
+}
+
+func TestPublicKeyVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+
+	digest := sha512.Sum512(msg)
+	ph := ed25519.SignerOptions{Hash: crypto.SHA512, Scheme: ed25519.ED25519Ph}
+	sig := ed25519.Sign(priv, msg)
+	sigPh := ed25519.SignPh(priv, msg, "")
+	for _, v := range []struct {
+		msg  []byte
+		sig  []byte
+		opts crypto.SignerOpts
+		want bool
+	}{
+		{msg, sig, crypto.Hash(0), true},
+		{digest[:], sig, crypto.SHA512, false},
+		{digest[:], sigPh, crypto.SHA512, true},
+		{msg, sigPh, crypto.SHA512, false},
+		{msg, sigPh, ph, true},
+		{msg, sigPh, crypto.Hash(0), false},
+		{msg, sigPh, crypto.SHA256, false},
+	} {
+		got := pub.Verify(v.msg, v.sig, v.opts)
+		if got != v.want {
+			test.ReportError(t, got, v.want, v.opts)
+		}
+	}
+
+	// PublicKey can be used through an interface.
+	var verifier interface {
+		Verify(message, signature []byte, opts crypto.SignerOpts) bool
+	} = pub
+	if !verifier.Verify(msg, sig, crypto.Hash(0)) {
+		test.ReportError(t, false, true)
+	}
+}
+
+func TestPhRequiresSHA512(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+
+	// crypto.SHA512 selects Ed25519ph on both sides.
+	digest := sha512.Sum512(msg)
+	sig, err := priv.Sign(nil, digest[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ed25519.SignPh(priv, msg, ""); !bytes.Equal(sig, want) {
+		test.ReportError(t, sig, want)
+	}
+	if !pub.Verify(digest[:], sig, crypto.SHA512) {
+		test.ReportError(t, false, true)
+	}
+	if !ed25519.VerifyAny(pub, digest[:], sig, crypto.SHA512) {
+		test.ReportError(t, false, true)
+	}
+
+	for _, opts := range []crypto.SignerOpts{
+		crypto.SHA256,
+		crypto.SHA384,
+		ed25519.SignerOptions{Scheme: ed25519.ED25519Ph, Hash: crypto.SHA256},
+		ed25519.SignerOptions{Scheme: ed25519.ED25519Ph, Hash: crypto.Hash(0)},
+	} {
+		_, err := priv.Sign(nil, msg, opts)
+		test.CheckIsErr(t, err, "Ed25519ph must only sign with SHA-512")
+		if ed25519.VerifyAny(pub, msg, sig, opts) {
+			test.ReportError(t, true, false, opts)
+		}
+		if pub.Verify(msg, sig, opts) {
+			test.ReportError(t, true, false, opts)
+		}
+	}
+}
+
+// Signs as crypto/tls and crypto/x509 do, that is, by hashing the message
+// and passing the digest to crypto.Signer with the hash as opts.
+func TestSignerDigest(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	var signer crypto.Signer = priv
+
+	h := crypto.SHA512.New()
+	_, _ = h.Write(msg)
+	digest := h.Sum(nil)
+	sig, err := signer.Sign(rand.Reader, digest, crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ed25519.SignPh(priv, msg, ""); !bytes.Equal(sig, want) {
+		test.ReportError(t, sig, want)
+	}
+	if !ed25519.VerifyPh(pub, msg, sig, "") {
+		test.ReportError(t, false, true)
+	}
+
+	// Digests of the wrong length are rejected.
+	for _, in := range [][]byte{nil, msg, digest[:sha512.Size-1], append(digest, 0)} {
+		_, err := signer.Sign(rand.Reader, in, crypto.SHA512)
+		test.CheckIsErr(t, err, "Ed25519ph must only sign 64-byte digests")
+		if ed25519.VerifyAny(pub, in, sig, crypto.SHA512) {
+			test.ReportError(t, true, false, in)
+		}
+	}
 }
 
 type badReader struct{}
@@ -247,3 +360,831 @@ func ExampleSignWithCtx() {
 	fmt.Println(ok)
 	// Output: true
 }
+
+// TestVerifyWithOptions is the decision table of the verification presets:
+// each entry is a signature showing which of them accept it. Verify always
+// agrees with RFC8032.
+func TestVerifyWithOptions(t *testing.T) {
+	hexPub := "03a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8"
+	// Identity point encoded canonically, and with y = p+1.
+	hexIdentity := "0100000000000000000000000000000000000000000000000000000000000000"
+	hexNonCanonicalPub := "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"
+	hexZero := "0000000000000000000000000000000000000000000000000000000000000000"
+	presets := []ed25519.VerifyOptions{
+		ed25519.RFC8032(),
+		ed25519.ZIP215(),
+		ed25519.LibsodiumCompat(),
+	}
+
+	for _, v := range []struct {
+		name string
+		pub  string
+		msg  string
+		sig  string
+		want [3]bool // RFC8032, ZIP215, LibsodiumCompat
+	}{
+		{
+			name: "valid",
+			pub:  hexPub,
+			msg:  "test message",
+			sig: "e7a1783d7f86e07c31f651f2cf57a378925525277d50331f2b3da54773e9b7c2" +
+				"bcb709e3ee3dae93ffd7b4375ca7ea5f1cd8919aa7dbfc96b2651905bed69708",
+			want: [3]bool{true, true, true},
+		},
+		{
+			// R has a component of order 8.
+			name: "torsionR",
+			pub:  hexPub,
+			msg:  "test message",
+			sig: "98519eadf35b995233b51b5cd23e9cc5a28b639b5a4af0ec903cb960d81b7819" +
+				"c7b7395de0ca6fddb07ec71d672b6014594c2c9161fc80eb1b382297adfe5d08",
+			want: [3]bool{false, true, false},
+		},
+		{
+			// S is replaced by S+order, which is less than 2^253.
+			name: "nonCanonicalS",
+			pub:  hexPub,
+			msg:  "message 0",
+			sig: "d53092d5dcf378d2b4529b979efef9fece2855daa727333beef58eadee5569cf" +
+				"d5e68fa9ac2f2f1b36e851534a54b2adb32f0be554667900fb5c01d33891bd12",
+			want: [3]bool{false, false, true},
+		},
+		{
+			name: "nonCanonicalPublicKey",
+			pub:  hexNonCanonicalPub,
+			msg:  "any message",
+			sig: "b862409fb5c4c4123df2abf7462b88f041ad36dd6864ce872fd5472be363c5b1" +
+				"0700000000000000000000000000000000000000000000000000000000000000",
+			want: [3]bool{false, true, false},
+		},
+		{
+			// Small-order public key and R, with S = 0: the equations
+			// hold for any message, and small-order points are accepted.
+			name: "smallOrder",
+			pub:  hexIdentity,
+			msg:  "any message",
+			sig:  hexIdentity + hexZero,
+			want: [3]bool{true, true, true},
+		},
+		{
+			// As above, but R is the identity encoded with y = p+1.
+			name: "nonCanonicalR",
+			pub:  hexIdentity,
+			msg:  "any message",
+			sig:  hexNonCanonicalPub + hexZero,
+			want: [3]bool{false, true, false},
+		},
+		{
+			// The "valid" signature with S >= 2^253.
+			name: "largeS",
+			pub:  hexPub,
+			msg:  "test message",
+			sig: "e7a1783d7f86e07c31f651f2cf57a378925525277d50331f2b3da54773e9b7c2" +
+				"bcb709e3ee3dae93ffd7b4375ca7ea5f1cd8919aa7dbfc96b2651905bed697e8",
+			want: [3]bool{false, false, false},
+		},
+		{
+			// The "valid" signature of another message.
+			name: "wrongMessage",
+			pub:  hexPub,
+			msg:  "test message!",
+			sig: "e7a1783d7f86e07c31f651f2cf57a378925525277d50331f2b3da54773e9b7c2" +
+				"bcb709e3ee3dae93ffd7b4375ca7ea5f1cd8919aa7dbfc96b2651905bed69708",
+			want: [3]bool{false, false, false},
+		},
+	} {
+		pub, _ := hex.DecodeString(v.pub)
+		sig, _ := hex.DecodeString(v.sig)
+		for i, opts := range presets {
+			got := ed25519.VerifyWithOptions(pub, []byte(v.msg), sig, opts)
+			want := v.want[i]
+			if got != want {
+				test.ReportError(t, got, want, v.name, opts)
+			}
+		}
+		got := ed25519.Verify(pub, []byte(v.msg), sig)
+		want := v.want[0]
+		if got != want {
+			test.ReportError(t, got, want, v.name)
+		}
+	}
+
+	t.Run("Verify", func(t *testing.T) {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte("message")
+		sig := ed25519.Sign(priv, msg)
+		for _, opts := range []ed25519.VerifyOptions{
+			{Cofactored: false, AllowNonCanonical: false, RequireCanonicalS: false},
+			{Cofactored: false, AllowNonCanonical: true, RequireCanonicalS: false},
+			{Cofactored: true, AllowNonCanonical: false, RequireCanonicalS: false},
+			{Cofactored: true, AllowNonCanonical: true, RequireCanonicalS: true},
+		} {
+			got := ed25519.VerifyWithOptions(pub, msg, sig, opts)
+			want := ed25519.Verify(pub, msg, sig)
+			if got != want {
+				test.ReportError(t, got, want, opts)
+			}
+		}
+	})
+}
+
+func BenchmarkVerifyWithOptions(b *testing.B) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := make([]byte, 128)
+	_, _ = rand.Read(msg)
+	sig := ed25519.Sign(priv, msg)
+
+	for _, v := range []struct {
+		name string
+		opts ed25519.VerifyOptions
+	}{
+		{"RFC8032", ed25519.RFC8032()},
+		{"ZIP215", ed25519.ZIP215()},
+		{"LibsodiumCompat", ed25519.LibsodiumCompat()},
+	} {
+		opts := v.opts
+		b.Run(v.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ed25519.VerifyWithOptions(pub, msg, sig, opts)
+			}
+		})
+	}
+}
+
+func TestPublicFromScalar(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		_, _ = rand.Read(seed)
+
+		h := sha512.Sum512(seed)
+		var scalar [32]byte
+		copy(scalar[:], h[:32])
+		scalar[0] &= 248
+		scalar[31] = (scalar[31] & 127) | 64
+
+		got := ed25519.PublicFromScalar(scalar)
+		want := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, seed)
+		}
+	}
+}
+
+func TestExpandedSecret(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		_, _ = rand.Read(seed)
+		priv := ed25519.NewKeyFromSeed(seed)
+		secret := priv.ExpandedSecret()
+
+		var scalar [32]byte
+		copy(scalar[:], secret[:32])
+		if scalar[0]&7 != 0 || scalar[31]&0xC0 != 0x40 {
+			test.ReportError(t, scalar, "clamped scalar", seed)
+		}
+
+		got := ed25519.PublicFromScalar(scalar)
+		want := priv.Public().(ed25519.PublicKey)
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, seed)
+		}
+
+		h := sha512.Sum512(seed)
+		if !bytes.Equal(secret[32:], h[32:]) {
+			test.ReportError(t, secret[32:], h[32:], seed)
+		}
+	}
+}
+
+func TestSignWithScalar(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		_, _ = rand.Read(seed)
+		priv := ed25519.NewKeyFromSeed(seed)
+		secret := priv.ExpandedSecret()
+		msg := make([]byte, i)
+		_, _ = rand.Read(msg)
+
+		var scalar, prefix [32]byte
+		copy(scalar[:], secret[:32])
+		copy(prefix[:], secret[32:])
+		if p := priv.NoncePrefix(); p != prefix {
+			test.ReportError(t, p, prefix, seed)
+		}
+		got, err := ed25519.SignWithScalar(scalar, priv.NoncePrefix(), msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ed25519.Sign(priv, msg)
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, seed, msg)
+		}
+
+		// An unclamped scalar, as a threshold share would be, verifies
+		// under its own public key.
+		_, _ = rand.Read(scalar[:])
+		sig, err := ed25519.SignWithScalar(scalar, prefix, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := ed25519.PublicFromScalar(scalar)
+		if !ed25519.Verify(pub, msg, sig) {
+			test.ReportError(t, false, true, scalar, msg)
+		}
+	}
+
+	var zero, prefix [32]byte
+	_, err := ed25519.SignWithScalar(zero, prefix, nil)
+	test.CheckIsErr(t, err, "SignWithScalar must fail with a zero scalar")
+}
+
+func TestNewKeyFromIKM(t *testing.T) {
+	ikm := []byte("input key material")
+	for _, v := range []struct {
+		salt, info []byte
+		seed       string
+	}{
+		{
+			[]byte("salt"), []byte("ed25519 key"),
+			"3f913329dd8363165fcaf0ecf7f16f58dc0c8d0e52475705462428c6edb30912",
+		},
+		{
+			nil, nil,
+			"fb1b86549e941b81821a89ac6ba7c4f93465077b3f2af94352ebf1d041efcd3c",
+		},
+	} {
+		priv := ed25519.NewKeyFromIKM(ikm, v.salt, v.info)
+		want, _ := hex.DecodeString(v.seed)
+		if got := priv.Seed(); !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, v.salt, v.info)
+		}
+		if want := ed25519.NewKeyFromSeed(want); !priv.Equal(want) {
+			test.ReportError(t, priv, want, v.salt, v.info)
+		}
+	}
+}
+
+func TestVerifierCache(t *testing.T) {
+	const numKeys = 8
+	const cacheSize = 4
+	var pubs [numKeys]ed25519.PublicKey
+	var sigs [numKeys][]byte
+	msg := []byte("test message")
+	for i := range pubs {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(i)
+		priv := ed25519.NewKeyFromSeed(seed)
+		pubs[i] = priv.Public().(ed25519.PublicKey)
+		sigs[i] = ed25519.Sign(priv, msg)
+	}
+	wrongPub := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	for i := range wrongPub {
+		wrongPub[i] = 0xff
+	}
+
+	cache := ed25519.NewVerifierCache(cacheSize)
+	var wg sync.WaitGroup
+	errs := make(chan error, numKeys)
+	for g := 0; g < numKeys; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for j := 0; j < 4*numKeys; j++ {
+				i := (g + j) % numKeys
+				if !cache.Verify(pubs[i], msg, sigs[i]) {
+					errs <- fmt.Errorf("valid signature %v rejected", i)
+					return
+				}
+				k := (i + 1) % numKeys
+				if cache.Verify(pubs[k], msg, sigs[i]) {
+					errs <- fmt.Errorf("signature %v accepted under key %v", i, k)
+					return
+				}
+				if cache.Verify(wrongPub, msg, sigs[i]) {
+					errs <- fmt.Errorf("invalid public key accepted")
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	got := cache.Len()
+	want := cacheSize
+	if got != want {
+		test.ReportError(t, got, want)
+	}
+}
+
+func TestNilAndEmptyMessage(t *testing.T) {
+	// Test 1 of RFC-8032, which signs the empty message.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	want, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	empty := []byte{}
+
+	for _, v := range []struct {
+		name   string
+		sign   func(msg []byte) []byte
+		verify func(msg, sig []byte) bool
+	}{
+		{
+			"Ed25519",
+			func(msg []byte) []byte { return ed25519.Sign(priv, msg) },
+			func(msg, sig []byte) bool { return ed25519.Verify(pub, msg, sig) },
+		},
+		{
+			"Ed25519Ph",
+			func(msg []byte) []byte { return ed25519.SignPh(priv, msg, "") },
+			func(msg, sig []byte) bool { return ed25519.VerifyPh(pub, msg, sig, "") },
+		},
+		{
+			"Ed25519Ctx",
+			func(msg []byte) []byte { return ed25519.SignWithCtx(priv, msg, "ctx") },
+			func(msg, sig []byte) bool { return ed25519.VerifyWithCtx(pub, msg, sig, "ctx") },
+		},
+		{
+			"crypto.Signer",
+			func(msg []byte) []byte {
+				sig, err := priv.Sign(nil, msg, crypto.Hash(0))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return sig
+			},
+			func(msg, sig []byte) bool {
+				return ed25519.VerifyAny(pub, msg, sig, crypto.Hash(0))
+			},
+		},
+	} {
+		sigNil := v.sign(nil)
+		sigEmpty := v.sign(empty)
+		if !bytes.Equal(sigNil, sigEmpty) {
+			test.ReportError(t, sigNil, sigEmpty, v.name)
+		}
+		for _, msg := range [][]byte{nil, empty} {
+			for _, sig := range [][]byte{sigNil, sigEmpty} {
+				if !v.verify(msg, sig) {
+					t.Fatalf("%v: signature of empty message rejected", v.name)
+				}
+			}
+		}
+	}
+
+	got := ed25519.Sign(priv, nil)
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, got, want)
+	}
+}
+
+func TestKeyBytes(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	privCopy := append(ed25519.PrivateKey{}, priv...)
+	pubCopy := append(ed25519.PublicKey{}, pub...)
+
+	privBytes := priv.Bytes()
+	pubBytes := pub.Bytes()
+	if !bytes.Equal(privBytes, priv) || !bytes.Equal(pubBytes, pub) {
+		t.Fatal("Bytes does not return the key")
+	}
+
+	for i := range privBytes {
+		privBytes[i] ^= 0xff
+	}
+	for i := range pubBytes {
+		pubBytes[i] ^= 0xff
+	}
+	if !priv.Equal(privCopy) {
+		test.ReportError(t, priv, privCopy)
+	}
+	if !pub.Equal(pubCopy) {
+		test.ReportError(t, pub, pubCopy)
+	}
+}
+
+func TestSignBatch(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, _ = rand.Read(seed)
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+
+	msgs := make([][]byte, 16)
+	for i := range msgs {
+		msgs[i] = make([]byte, i)
+		_, _ = rand.Read(msgs[i])
+	}
+	sigs, err := ed25519.SignBatch(priv, msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != len(msgs) {
+		test.ReportError(t, len(sigs), len(msgs))
+	}
+	for i := range msgs {
+		want := ed25519.Sign(priv, msgs[i])
+		if !bytes.Equal(sigs[i], want) {
+			test.ReportError(t, sigs[i], want, i)
+		}
+		if !ed25519.Verify(pub, msgs[i], sigs[i]) {
+			t.Fatalf("signature %v does not verify", i)
+		}
+	}
+
+	_, err = ed25519.SignBatch(priv[:ed25519.SeedSize], msgs)
+	test.CheckIsErr(t, err, "SignBatch must fail with a bad private key")
+}
+
+func TestSignFromSeed(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, _ = rand.Read(seed)
+	msg := []byte("message")
+	priv := ed25519.NewKeyFromSeed(seed)
+	wantPub := priv.Public().(ed25519.PublicKey)
+
+	for _, ph := range []bool{false, true} {
+		sig, pub, err := ed25519.SignFromSeed(seed, msg, ph)
+		test.CheckNoErr(t, err, "SignFromSeed failed")
+		want, ok := ed25519.Sign(priv, msg), ed25519.Verify(pub, msg, sig)
+		if ph {
+			want, ok = ed25519.SignPh(priv, msg, ""), ed25519.VerifyPh(pub, msg, sig, "")
+		}
+		if !bytes.Equal(sig, want) {
+			test.ReportError(t, sig, want, ph)
+		}
+		if !bytes.Equal(pub, wantPub) {
+			test.ReportError(t, pub, wantPub, ph)
+		}
+		if !ok {
+			t.Fatalf("signature does not verify, ph: %v", ph)
+		}
+	}
+
+	_, _, err := ed25519.SignFromSeed(seed[1:], msg, false)
+	test.CheckIsErr(t, err, "SignFromSeed must fail with a bad seed")
+}
+
+func BenchmarkSignBatch(b *testing.B) {
+	const numMsgs = 64
+	seed := make([]byte, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	msgs := make([][]byte, numMsgs)
+	for i := range msgs {
+		msgs[i] = []byte{byte(i)}
+	}
+
+	b.Run("Sign", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range msgs {
+				_ = ed25519.Sign(priv, msgs[j])
+			}
+		}
+	})
+	b.Run("SignBatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = ed25519.SignBatch(priv, msgs)
+		}
+	})
+}
+
+func TestVerifyCanonicalizations(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := []byte("message")
+	sig := ed25519.Sign(priv, msg)
+
+	candidates := [][]byte{
+		append([]byte{0, 0, 0, byte(len(msg))}, msg...),
+		[]byte("message\n"),
+		msg,
+		[]byte("message\r\n"),
+	}
+	i, ok := ed25519.VerifyCanonicalizations(pub, sig, candidates)
+	if !ok || i != 2 {
+		test.ReportError(t, i, 2, ok)
+	}
+
+	for _, v := range []struct {
+		name       string
+		pub        ed25519.PublicKey
+		sig        []byte
+		candidates [][]byte
+	}{
+		{"no match", pub, sig, [][]byte{candidates[0], candidates[1], candidates[3]}},
+		{"no candidates", pub, sig, nil},
+		{"bad public key", pub[1:], sig, candidates},
+		{"bad signature", pub, sig[1:], candidates},
+	} {
+		i, ok := ed25519.VerifyCanonicalizations(v.pub, v.sig, v.candidates)
+		if ok || i != -1 {
+			test.ReportError(t, i, -1, v.name)
+		}
+	}
+}
+
+func TestVerifyBatchSameKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, _ = rand.Read(seed)
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+
+	msgs := make([][]byte, 16)
+	for i := range msgs {
+		msgs[i] = make([]byte, i)
+		_, _ = rand.Read(msgs[i])
+	}
+	sigs, err := ed25519.SignBatch(priv, msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, valid := ed25519.VerifyBatchSameKey(pub, msgs, sigs)
+	if !ok {
+		test.ReportError(t, ok, true)
+	}
+	for i := range valid {
+		if !valid[i] {
+			test.ReportError(t, valid[i], true, i)
+		}
+	}
+
+	// Invalidates a single signature, which must be localized.
+	const bad = 5
+	sigs[bad][0] ^= 1
+	ok, valid = ed25519.VerifyBatchSameKey(pub, msgs, sigs)
+	if ok {
+		test.ReportError(t, ok, false)
+	}
+	for i := range valid {
+		if got, want := valid[i], i != bad; got != want {
+			test.ReportError(t, got, want, i)
+		}
+	}
+
+	ok, valid = ed25519.VerifyBatchSameKey(pub, msgs, sigs[:1])
+	if ok || valid != nil {
+		test.ReportError(t, ok, false, valid)
+	}
+	ok, valid = ed25519.VerifyBatchSameKey(pub, nil, nil)
+	if !ok || len(valid) != 0 {
+		test.ReportError(t, ok, true, valid)
+	}
+}
+
+func TestVerifyBatchSameKeyTorsion(t *testing.T) {
+	// Signatures from TestVerifyWithOptions: torsion has an R with a
+	// component of order 8, which only the cofactored equation accepts.
+	pub, _ := hex.DecodeString("03a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8")
+	good, _ := hex.DecodeString("e7a1783d7f86e07c31f651f2cf57a378925525277d50331f2b3da54773e9b7c2" +
+		"bcb709e3ee3dae93ffd7b4375ca7ea5f1cd8919aa7dbfc96b2651905bed69708")
+	torsion, _ := hex.DecodeString("98519eadf35b995233b51b5cd23e9cc5a28b639b5a4af0ec903cb960d81b7819" +
+		"c7b7395de0ca6fddb07ec71d672b6014594c2c9161fc80eb1b382297adfe5d08")
+	bad := append([]byte{}, good...)
+	bad[0] ^= 1
+	msg := []byte("test message")
+
+	// The result for the torsion signature does not depend on whether the
+	// batch holds, or falls back to verifying one by one.
+	for _, v := range []struct {
+		name string
+		sigs [][]byte
+		want []bool
+	}{
+		{"good batch", [][]byte{good, torsion, good}, []bool{true, true, true}},
+		{"bad batch", [][]byte{good, torsion, bad}, []bool{true, true, false}},
+		{"bad batch first", [][]byte{bad, torsion, good}, []bool{false, true, true}},
+	} {
+		msgs := [][]byte{msg, msg, msg}
+		ok, valid := ed25519.VerifyBatchSameKey(pub, msgs, v.sigs)
+		wantOk := v.want[0] && v.want[1] && v.want[2]
+		if ok != wantOk {
+			test.ReportError(t, ok, wantOk, v.name)
+		}
+		for i := range valid {
+			if valid[i] != v.want[i] {
+				test.ReportError(t, valid[i], v.want[i], v.name, i)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifyBatchSameKey(b *testing.B) {
+	const numMsgs = 64
+	seed := make([]byte, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	msgs := make([][]byte, numMsgs)
+	for i := range msgs {
+		msgs[i] = []byte{byte(i)}
+	}
+	sigs, _ := ed25519.SignBatch(priv, msgs)
+
+	b.Run("Verify", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range msgs {
+				_ = ed25519.Verify(pub, msgs[j], sigs[j])
+			}
+		}
+	})
+	b.Run("VerifyBatchSameKey", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = ed25519.VerifyBatchSameKey(pub, msgs, sigs)
+		}
+	})
+}
+
+func TestDetachedSignature(t *testing.T) {
+	msg := []byte("message")
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	pub2, priv2, _ := ed25519.GenerateKey(rand.Reader)
+
+	sig := ed25519.SignDetached(priv, msg)
+	if sig.KeyID != pub.KeyID() {
+		test.ReportError(t, sig.KeyID, pub.KeyID())
+	}
+	if !bytes.Equal(sig.Signature[:], ed25519.Sign(priv, msg)) {
+		test.ReportError(t, sig.Signature, ed25519.Sign(priv, msg))
+	}
+
+	blob, err := sig.MarshalBinary()
+	test.CheckNoErr(t, err, "marshaling must succeed")
+	if len(blob) != ed25519.DetachedSignatureSize {
+		test.ReportError(t, len(blob), ed25519.DetachedSignatureSize)
+	}
+	var got ed25519.DetachedSignature
+	test.CheckNoErr(t, got.UnmarshalBinary(blob), "unmarshaling must succeed")
+	if got != *sig {
+		test.ReportError(t, got, *sig)
+	}
+
+	if !ed25519.VerifyDetached(pub, msg, blob) {
+		test.ReportError(t, false, true, blob)
+	}
+	// A different key is rejected by its key id, and a valid signature
+	// under a mismatched key id is rejected too.
+	if ed25519.VerifyDetached(pub2, msg, blob) {
+		test.ReportError(t, true, false, blob)
+	}
+	other := ed25519.SignDetached(priv2, msg)
+	other.Signature = sig.Signature
+	blob2, _ := other.MarshalBinary()
+	if ed25519.VerifyDetached(pub, msg, blob2) {
+		test.ReportError(t, true, false, blob2)
+	}
+	if ed25519.VerifyDetached(pub, msg, blob[:len(blob)-1]) {
+		test.ReportError(t, true, false, blob)
+	}
+	test.CheckIsErr(t, got.UnmarshalBinary(blob[1:]), "unmarshaling must fail")
+}
+
+func TestContextLength(t *testing.T) {
+	msg := []byte("message")
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	ctxMax := string(make([]byte, ed25519.ContextMaxSize))
+	ctxLong := string(make([]byte, ed25519.ContextMaxSize+1))
+
+	// A context of ContextMaxSize bytes is accepted.
+	sig := ed25519.SignPh(priv, msg, ctxMax)
+	if !ed25519.VerifyPh(pub, msg, sig, ctxMax) {
+		test.ReportError(t, false, true, len(ctxMax))
+	}
+	sig = ed25519.SignWithCtx(priv, msg, ctxMax)
+	if !ed25519.VerifyWithCtx(pub, msg, sig, ctxMax) {
+		test.ReportError(t, false, true, len(ctxMax))
+	}
+
+	// One more byte is rejected by all the variants.
+	for _, sign := range []func(){
+		func() { ed25519.SignPh(priv, msg, ctxLong) },
+		func() { ed25519.SignWithCtx(priv, msg, ctxLong) },
+	} {
+		if err := test.CheckPanic(sign); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ed25519.VerifyPh(pub, msg, sig, ctxLong) {
+		test.ReportError(t, true, false, len(ctxLong))
+	}
+	if ed25519.VerifyWithCtx(pub, msg, sig, ctxLong) {
+		test.ReportError(t, true, false, len(ctxLong))
+	}
+	for _, scheme := range []ed25519.SchemeID{ed25519.ED25519Ph, ed25519.ED25519Ctx} {
+		opts := ed25519.SignerOptions{Context: ctxLong, Scheme: scheme}
+		if scheme == ed25519.ED25519Ph {
+			opts.Hash = crypto.SHA512
+		}
+		_, err := priv.Sign(nil, msg, opts)
+		if err != ed25519.ErrContextTooLong {
+			test.ReportError(t, err, ed25519.ErrContextTooLong, scheme)
+		}
+		if ed25519.VerifyAny(pub, msg, sig, opts) {
+			test.ReportError(t, true, false, scheme)
+		}
+	}
+
+	// An empty context is valid for Ed25519ph, but not for Ed25519ctx,
+	// which would otherwise be the same as pure Ed25519.
+	sig = ed25519.SignPh(priv, msg, "")
+	if !ed25519.VerifyPh(pub, msg, sig, "") {
+		test.ReportError(t, false, true)
+	}
+	if err := test.CheckPanic(func() { ed25519.SignWithCtx(priv, msg, "") }); err != nil {
+		t.Fatal(err)
+	}
+	if ed25519.VerifyWithCtx(pub, msg, ed25519.Sign(priv, msg), "") {
+		test.ReportError(t, true, false)
+	}
+	_, err := priv.Sign(nil, msg, ed25519.SignerOptions{Scheme: ed25519.ED25519Ctx})
+	test.CheckIsErr(t, err, "signing with an empty context must fail")
+}
+
+func TestSignRandomized(t *testing.T) {
+	msg := []byte("message")
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	sig1, err := priv.SignRandomized(rand.Reader, msg)
+	test.CheckNoErr(t, err, "signing must succeed")
+	sig2, err := priv.SignRandomized(nil, msg)
+	test.CheckNoErr(t, err, "signing must succeed")
+	if bytes.Equal(sig1, sig2) {
+		test.ReportError(t, sig1, sig2, msg)
+	}
+	if bytes.Equal(sig1, ed25519.Sign(priv, msg)) {
+		test.ReportError(t, sig1, ed25519.Sign(priv, msg), msg)
+	}
+	for _, sig := range [][]byte{sig1, sig2} {
+		if !ed25519.Verify(pub, msg, sig) {
+			test.ReportError(t, false, true, sig)
+		}
+		if ed25519.Verify(pub, []byte("other message"), sig) {
+			test.ReportError(t, true, false, sig)
+		}
+	}
+
+	// The same randomness gives the same signature.
+	var Z [64]byte
+	sig1, _ = priv.SignRandomized(bytes.NewReader(Z[:]), msg)
+	sig2, _ = priv.SignRandomized(bytes.NewReader(Z[:]), msg)
+	if !bytes.Equal(sig1, sig2) {
+		test.ReportError(t, sig1, sig2, msg)
+	}
+
+	_, err = priv.SignRandomized(badReader{}, msg)
+	test.CheckIsErr(t, err, "signing must fail")
+	_, err = priv.SignRandomized(bytes.NewReader(Z[:63]), msg)
+	test.CheckIsErr(t, err, "signing must fail")
+}
+
+func TestVerifyAnyKey(t *testing.T) {
+	const numKeys = 4
+	msg := []byte("message")
+	pubs := make([]ed25519.PublicKey, numKeys)
+	privs := make([]ed25519.PrivateKey, numKeys)
+	for i := range pubs {
+		pubs[i], privs[i], _ = ed25519.GenerateKey(rand.Reader)
+	}
+
+	for _, verify := range []func([]ed25519.PublicKey, []byte, []byte) (int, bool){
+		ed25519.VerifyAnyKey,
+		ed25519.VerifyAnyKeyConstantTime,
+	} {
+		for want := range privs {
+			sig := ed25519.Sign(privs[want], msg)
+			got, ok := verify(pubs, msg, sig)
+			if !ok || got != want {
+				test.ReportError(t, got, want, ok)
+			}
+			got, ok = verify(pubs, []byte("other message"), sig)
+			if ok || got != -1 {
+				test.ReportError(t, got, -1, ok)
+			}
+		}
+
+		// The first of repeated keys is returned.
+		sig := ed25519.Sign(privs[2], msg)
+		got, ok := verify([]ed25519.PublicKey{pubs[0], pubs[2], pubs[2]}, msg, sig)
+		if !ok || got != 1 {
+			test.ReportError(t, got, 1, ok)
+		}
+
+		// No key matches.
+		_, priv, _ := ed25519.GenerateKey(rand.Reader)
+		sig = ed25519.Sign(priv, msg)
+		for _, keys := range [][]ed25519.PublicKey{pubs, nil, {pubs[0][:1]}} {
+			got, ok = verify(keys, msg, sig)
+			if ok || got != -1 {
+				test.ReportError(t, got, -1, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// Prefixes of the comment lines of minisign signature files.
+	minisignUntrusted = "untrusted comment: "
+	minisignTrusted   = "trusted comment: "
+	// minisignPrefixSize is the size of the algorithm and key id that
+	// prefix minisign keys and signatures.
+	minisignPrefixSize = 2 + KeyIDSize
+)
+
+var (
+	// ErrMinisignFormat is returned for minisign keys and signature files
+	// that are malformed.
+	ErrMinisignFormat = errors.New("ed25519: malformed minisign data")
+	// ErrMinisignInvalid is returned by MinisignVerify for signatures that
+	// do not verify.
+	ErrMinisignInvalid = errors.New("ed25519: invalid minisign signature")
+)
+
+// Signature algorithms of minisign, namely Ed25519 of the message itself,
+// as in signify, and Ed25519 of its BLAKE2b-512 digest.
+var (
+	minisignAlgLegacy  = []byte("Ed")
+	minisignAlgPrehash = []byte("ED")
+)
+
+// MinisignPublicKey returns the encoding of pub used by minisign, that is,
+// the base64 encoding of the algorithm "Ed", the key id of pub as returned
+// by KeyID, and pub itself. It is the key to give to minisign -P to verify
+// the signatures of MinisignSign.
+func MinisignPublicKey(pub PublicKey) string {
+	id := pub.KeyID()
+	b := make([]byte, 0, minisignPrefixSize+PublicKeySize)
+	b = append(b, minisignAlgLegacy...)
+	b = append(b, id[:]...)
+	b = append(b, pub...)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// MinisignSign signs the message with privateKey, and returns the minisign
+// signature file of it, which holds, on separate lines:
+//
+//   untrusted comment: <text>
+//   base64 of "ED", the key id, and the signature of BLAKE2b-512(message)
+//   trusted comment: <trustedComment>
+//   base64 of the global signature of the signature and trustedComment
+//
+// As in minisign, the message is prehashed, and the global signature binds
+// the trusted comment to the signature. The key id is that of the public
+// key, as returned by KeyID. It returns ErrMinisignFormat if trustedComment
+// spans several lines.
+// It will panic if len(privateKey) is not PrivateKeySize.
+func MinisignSign(privateKey PrivateKey, message, trustedComment []byte) ([]byte, error) {
+	if bytes.ContainsAny(trustedComment, "\r\n") {
+		return nil, ErrMinisignFormat
+	}
+	id := PublicKey(privateKey[SeedSize:]).KeyID()
+	digest := blake2b.Sum512(message)
+
+	sig := make([]byte, 0, minisignPrefixSize+SignatureSize)
+	sig = append(sig, minisignAlgPrehash...)
+	sig = append(sig, id[:]...)
+	sig = append(sig, Sign(privateKey, digest[:])...)
+	global := Sign(privateKey, append(sig[minisignPrefixSize:], trustedComment...))
+
+	var b bytes.Buffer
+	b.WriteString(minisignUntrusted + "signature from circl secret key\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(sig) + "\n")
+	b.WriteString(minisignTrusted)
+	b.Write(trustedComment)
+	b.WriteString("\n" + base64.StdEncoding.EncodeToString(global) + "\n")
+	return b.Bytes(), nil
+}
+
+// MinisignVerify checks the minisign signature file sigFile of message
+// against publicKey, and returns its trusted comment. The public key is
+// either its base64 encoding, as given to minisign -P, or the content of a
+// minisign public key file. Both prehashed and legacy signatures are
+// accepted, as minisign does.
+//
+// It returns ErrMinisignFormat if publicKey or sigFile are malformed, and
+// ErrMinisignInvalid if the key ids differ or either signature is not
+// valid. The trusted comment must not be trusted unless err is nil.
+func MinisignVerify(publicKey, message, sigFile []byte) (trustedComment []byte, err error) {
+	lines := bytes.Split(bytes.TrimSpace(publicKey), []byte("\n"))
+	pk, err := minisignDecode(lines[len(lines)-1], minisignPrefixSize+PublicKeySize)
+	if err != nil || !bytes.Equal(pk[:2], minisignAlgLegacy) {
+		return nil, ErrMinisignFormat
+	}
+
+	lines = bytes.Split(bytes.TrimRight(sigFile, "\r\n"), []byte("\n"))
+	if len(lines) != 4 {
+		return nil, ErrMinisignFormat
+	}
+	for i := range lines {
+		lines[i] = bytes.TrimSuffix(lines[i], []byte("\r"))
+	}
+	if !bytes.HasPrefix(lines[0], []byte(minisignUntrusted)) ||
+		!bytes.HasPrefix(lines[2], []byte(minisignTrusted)) {
+		return nil, ErrMinisignFormat
+	}
+	sig, err := minisignDecode(lines[1], minisignPrefixSize+SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	global, err := minisignDecode(lines[3], SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	trustedComment = lines[2][len(minisignTrusted):]
+
+	if subtle.ConstantTimeCompare(sig[2:minisignPrefixSize], pk[2:minisignPrefixSize]) != 1 {
+		return nil, ErrMinisignInvalid
+	}
+	pub := PublicKey(pk[minisignPrefixSize:])
+	switch {
+	case bytes.Equal(sig[:2], minisignAlgLegacy):
+	case bytes.Equal(sig[:2], minisignAlgPrehash):
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	default:
+		return nil, ErrMinisignFormat
+	}
+	if !Verify(pub, message, sig[minisignPrefixSize:]) {
+		return nil, ErrMinisignInvalid
+	}
+	signed := append(append([]byte{}, sig[minisignPrefixSize:]...), trustedComment...)
+	if !Verify(pub, signed, global) {
+		return nil, ErrMinisignInvalid
+	}
+	return append([]byte{}, trustedComment...), nil
+}
+
+// minisignDecode returns the base64 decoding of s, and checks that it is
+// size bytes long.
+func minisignDecode(s []byte, size int) ([]byte, error) {
+	b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+	n, err := base64.StdEncoding.Decode(b, s)
+	if err != nil || n != size {
+		return nil, ErrMinisignFormat
+	}
+	return b[:n], nil
+}
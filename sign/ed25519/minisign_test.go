@@ -0,0 +1,90 @@
+package ed25519_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/sign/ed25519"
+)
+
+// Signature of "test" made by minisign with a legacy (not prehashed) key.
+const (
+	minisignPub = "untrusted comment: minisign public key 37620F1842B4E81F\n" +
+		"RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3\n"
+	minisignSig = "untrusted comment: signature from minisign secret key\n" +
+		"RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=\n" +
+		"trusted comment: timestamp:1555779966\tfile:test\n" +
+		"QtKMXWyYcwdpZAlPF7tE2ENJkRd1ujvKjlj1m9RtHTBnZPa5WKU5uWRs5GoP5M/VqE81QFuMKI5k/SfNQUaOAA==\n"
+)
+
+func TestMinisignVector(t *testing.T) {
+	for _, pub := range []string{minisignPub, "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"} {
+		got, err := ed25519.MinisignVerify([]byte(pub), []byte("test"), []byte(minisignSig))
+		test.CheckNoErr(t, err, "minisign signature must verify")
+		want := []byte("timestamp:1555779966\tfile:test")
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want)
+		}
+	}
+
+	_, err := ed25519.MinisignVerify([]byte(minisignPub), []byte("tesT"), []byte(minisignSig))
+	if err != ed25519.ErrMinisignInvalid {
+		test.ReportError(t, err, ed25519.ErrMinisignInvalid)
+	}
+	forged := bytes.Replace([]byte(minisignSig), []byte("file:test"), []byte("file:tesT"), 1)
+	_, err = ed25519.MinisignVerify([]byte(minisignPub), []byte("test"), forged)
+	if err != ed25519.ErrMinisignInvalid {
+		test.ReportError(t, err, ed25519.ErrMinisignInvalid)
+	}
+}
+
+func TestMinisign(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := []byte("message")
+	comment := []byte("timestamp:1555779966\tfile:message")
+	pk := []byte(ed25519.MinisignPublicKey(pub))
+
+	sig, err := ed25519.MinisignSign(priv, msg, comment)
+	test.CheckNoErr(t, err, "MinisignSign failed")
+	got, err := ed25519.MinisignVerify(pk, msg, sig)
+	test.CheckNoErr(t, err, "signature must verify")
+	if !bytes.Equal(got, comment) {
+		test.ReportError(t, got, comment)
+	}
+
+	crlf := bytes.Replace(sig, []byte("\n"), []byte("\r\n"), -1)
+	_, err = ed25519.MinisignVerify(pk, msg, crlf)
+	test.CheckNoErr(t, err, "CRLF line endings must be accepted")
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = ed25519.MinisignVerify([]byte(ed25519.MinisignPublicKey(otherPub)), msg, sig)
+	if err != ed25519.ErrMinisignInvalid {
+		test.ReportError(t, err, ed25519.ErrMinisignInvalid)
+	}
+
+	_, err = ed25519.MinisignSign(priv, msg, []byte("two\nlines"))
+	if err != ed25519.ErrMinisignFormat {
+		test.ReportError(t, err, ed25519.ErrMinisignFormat)
+	}
+
+	lines := bytes.Split(sig, []byte("\n"))
+	for _, v := range []struct {
+		name     string
+		pub, sig []byte
+	}{
+		{"bad public key", pk[1:], sig},
+		{"empty public key", nil, sig},
+		{"missing line", pk, bytes.Join(lines[1:], []byte("\n"))},
+		{"no untrusted comment", pk, append([]byte("comment: x"), sig[bytes.IndexByte(sig, '\n'):]...)},
+		{"bad signature encoding", pk, bytes.Replace(sig, lines[1], lines[1][1:], 1)},
+		{"bad global signature", pk, bytes.Replace(sig, lines[3], lines[1], 1)},
+		{"unknown algorithm", pk, bytes.Replace(sig, lines[1], append([]byte("X"), lines[1][1:]...), 1)},
+	} {
+		_, err := ed25519.MinisignVerify(v.pub, msg, v.sig)
+		if err != ed25519.ErrMinisignFormat {
+			test.ReportError(t, err, ed25519.ErrMinisignFormat, v.name)
+		}
+	}
+}
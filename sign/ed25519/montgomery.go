@@ -0,0 +1,66 @@
+package ed25519
+
+import (
+	"errors"
+
+	fp "github.com/cloudflare/circl/math/fp25519"
+)
+
+// ErrNonCanonicalY is returned when converting a public key whose
+// y-coordinate is not reduced modulo p.
+var ErrNonCanonicalY = errors.New("ed25519: non-canonical y-coordinate")
+
+// paramA is the coefficient A = 486662 of Curve25519, v^2 = u^3 + Au^2 + u.
+var paramA = fp.Elt{0x06, 0x6d, 0x07}
+
+// ToX25519 returns the u-coordinate of the point of Curve25519 that is
+// birationally equivalent to pub, that is, u = (1+y)/(1-y), as used to
+// reuse Ed25519 keys for X25519. It is ToX25519Checked, ignoring whether
+// the u-coordinate lies on the twist.
+func (pub PublicKey) ToX25519() ([]byte, error) {
+	u, _, err := pub.ToX25519Checked()
+	return u, err
+}
+
+// ToX25519Checked returns the u-coordinate of pub as ToX25519 does, and
+// whether it lies on the quadratic twist of Curve25519 rather than on the
+// curve itself. Only the y-coordinate of pub is used, so encodings that do
+// not decode to a point, as their x-coordinate does not exist, yield twist
+// points, which protocols that must reject them can detect. The identity,
+// y = 1, yields u = 0.
+//
+// It returns an error only if pub is not PublicKeySize bytes long, or if
+// its y-coordinate is not smaller than p.
+func (pub PublicKey) ToX25519Checked() (u []byte, onTwist bool, err error) {
+	if len(pub) != PublicKeySize {
+		return nil, false, errors.New("ed25519: bad public key length")
+	}
+	var y fp.Elt
+	copy(y[:], pub)
+	y[fp.Size-1] &= 0x7F
+	p := fp.P()
+	if !isLessThan(y[:], p[:]) {
+		return nil, false, ErrNonCanonicalY
+	}
+
+	// u = (1+y)/(1-y)
+	one, num, den, x := &fp.Elt{}, &fp.Elt{}, &fp.Elt{}, &fp.Elt{}
+	fp.SetOne(one)
+	fp.Add(num, one, &y)
+	fp.Sub(den, one, &y)
+	fp.Inv(den, den)
+	fp.Mul(x, num, den)
+	fp.Modp(x)
+
+	// u is on the curve iff u^3 + Au^2 + u = ((u + A)u + 1)u is a square.
+	t := &fp.Elt{}
+	fp.Add(t, x, &paramA)
+	fp.Mul(t, t, x)
+	fp.Add(t, t, one)
+	fp.Mul(t, t, x)
+	isQR := fp.InvSqrt(&fp.Elt{}, t, one)
+
+	u = make([]byte, fp.Size)
+	_ = fp.ToBytes(u, x)
+	return u, !isQR, nil
+}
@@ -0,0 +1,93 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/cloudflare/circl/dh/x25519"
+	"github.com/cloudflare/circl/internal/test"
+)
+
+func TestToX25519(t *testing.T) {
+	// The u-coordinate of a public key is the X25519 public key of the
+	// same secret scalar.
+	for i := 0; i < 32; i++ {
+		pub, priv, _ := GenerateKey(rand.Reader)
+		h := sha512.Sum512(priv.Seed())
+		var secret, want x25519.Key
+		copy(secret[:], h[:x25519.Size])
+		x25519.KeyGen(&want, &secret)
+
+		got, onTwist, err := pub.ToX25519Checked()
+		test.CheckNoErr(t, err, "ToX25519Checked failed")
+		if onTwist {
+			t.Fatalf("public key %x converted to a twist point", pub)
+		}
+		if !bytes.Equal(got, want[:]) {
+			test.ReportError(t, got, want, pub)
+		}
+		got, err = pub.ToX25519()
+		test.CheckNoErr(t, err, "ToX25519 failed")
+		if !bytes.Equal(got, want[:]) {
+			test.ReportError(t, got, want, pub)
+		}
+	}
+
+	// The identity maps to u = 0, on the curve.
+	id := make(PublicKey, PublicKeySize)
+	id[0] = 1
+	got, onTwist, err := id.ToX25519Checked()
+	test.CheckNoErr(t, err, "ToX25519Checked failed")
+	if onTwist || !bytes.Equal(got, make([]byte, 32)) {
+		test.ReportError(t, got, 0, onTwist)
+	}
+}
+
+func TestToX25519Twist(t *testing.T) {
+	// y = 2 has no x-coordinate, so u = (1+2)/(1-2) = -3 is on the twist.
+	pub := make(PublicKey, PublicKeySize)
+	pub[0] = 2
+	var P pointR1
+	if P.FromBytes(pub) {
+		t.Fatal("y = 2 must not decode to a point")
+	}
+	got, onTwist, err := pub.ToX25519Checked()
+	test.CheckNoErr(t, err, "ToX25519Checked failed")
+	if !onTwist {
+		t.Fatal("u = -3 must be on the twist")
+	}
+	want := make([]byte, 32)
+	for i := range want {
+		want[i] = 0xff
+	}
+	want[0] = 0xea
+	want[31] = 0x7f
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, got, want)
+	}
+
+	// An encoding is on the twist exactly when it does not decode.
+	for y := 2; y < 64; y++ {
+		pub[0] = byte(y)
+		_, onTwist, err := pub.ToX25519Checked()
+		test.CheckNoErr(t, err, "ToX25519Checked failed")
+		if onTwist == P.FromBytes(pub) {
+			test.ReportError(t, onTwist, !onTwist, y)
+		}
+	}
+
+	// Non-canonical y-coordinates are rejected.
+	p := []byte{
+		0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	}
+	for _, k := range []PublicKey{p, p[1:]} {
+		if _, _, err := k.ToX25519Checked(); err == nil {
+			t.Fatalf("%x must be rejected", []byte(k))
+		}
+	}
+}
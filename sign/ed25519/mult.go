@@ -141,8 +141,31 @@ const (
 	omegaVar = 5
 )
 
+// negKeyTable holds the odd multiples of a point as used by
+// doubleMultTable. Verification stores those of -A, the negated public key.
+type negKeyTable [1 << (omegaVar - 2)]pointR2
+
+// fromBytes sets T to the odd multiples of -A, where A is the point encoded
+// in k. Returns false if k is not a valid point encoding.
+func (T *negKeyTable) fromBytes(k []byte, canonical bool) bool {
+	var A pointR1
+	if ok := A.fromBytes(k, canonical); !ok {
+		return false
+	}
+	A.neg()
+	A.oddMultiples(T[:])
+	return true
+}
+
 // doubleMult returns P=mG+nQ.
 func (P *pointR1) doubleMult(Q *pointR1, m, n []byte) {
+	var TabQ negKeyTable
+	Q.oddMultiples(TabQ[:])
+	P.doubleMultTable(&TabQ, m, n)
+}
+
+// doubleMultTable returns P=mG+nQ, where TabQ holds the odd multiples of Q.
+func (P *pointR1) doubleMultTable(TabQ *negKeyTable, m, n []byte) {
 	nafFix := math.OmegaNAF(conv.BytesLe2BigInt(m), omegaFix)
 	nafVar := math.OmegaNAF(conv.BytesLe2BigInt(n), omegaVar)
 
@@ -152,8 +175,6 @@ func (P *pointR1) doubleMult(Q *pointR1, m, n []byte) {
 		nafFix = append(nafFix, make([]int32, len(nafVar)-len(nafFix))...)
 	}
 
-	var TabQ [1 << (omegaVar - 2)]pointR2
-	Q.oddMultiples(TabQ[:])
 	P.SetIdentity()
 	for i := len(nafFix) - 1; i >= 0; i-- {
 		P.double()
@@ -0,0 +1,90 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+)
+
+// nonce returns the nonce r and the commitment R computed by signAll for
+// the given key and message.
+func nonce(privateKey PrivateKey, message, ctx []byte, preHash bool) (r, R []byte) {
+	H := sha512.New()
+	PHM := message
+	if preHash {
+		_, _ = H.Write(message)
+		PHM = H.Sum(nil)
+		H.Reset()
+	}
+	_, _ = H.Write(privateKey[:SeedSize])
+	h := H.Sum(nil)
+	r = make([]byte, paramB)
+	R = make([]byte, paramB)
+	deriveNonce(r, R, H, h[paramB:], PHM, ctx, preHash)
+	return r, R
+}
+
+func TestNonce(t *testing.T) {
+	// Vectors from RFC-8032. The expected R is the first half of the
+	// signatures given in Section 7, and r was calculated independently.
+	for _, v := range []struct {
+		name, seed, msg, ctx string
+		preHash              bool
+		r, R                 string
+	}{
+		{
+			name: "TEST 1",
+			seed: "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			msg:  "",
+			r:    "f38907308c893deaf244787db4af53682249107418afc2edc58f75ac58a07404",
+			R:    "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155",
+		},
+		{
+			name: "TEST 2",
+			seed: "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			msg:  "72",
+			r:    "8fbfff709903dbcc23af59ab09657ea6697185a1b5072a52c83a5d9edb2e3308",
+			R:    "92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da",
+		},
+		{
+			name: "TEST 3",
+			seed: "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			msg:  "af82",
+			r:    "7f0ef0a21692a08638a952af0a9c6a77ce58ef56746b3f2f1c180c691db85605",
+			R:    "6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac",
+		},
+		{
+			name:    "TEST abc",
+			seed:    "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42",
+			msg:     "616263",
+			preHash: true,
+			r:       "21da51e1857b22fef03c40672418c54bb5184c556646aa5c11d7e01d54da3c06",
+			R:       "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae41",
+		},
+		{
+			name: "foo",
+			seed: "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6",
+			msg:  "f726936d19c800494e3fdaff20b276a8",
+			ctx:  "666f6f",
+			r:    "8424f8102fd0dd7422777ac776d1926bf4af1e228f8932ffd564e0852e91310c",
+			R:    "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a",
+		},
+	} {
+		seed, _ := hex.DecodeString(v.seed)
+		msg, _ := hex.DecodeString(v.msg)
+		ctx, _ := hex.DecodeString(v.ctx)
+		wantr, _ := hex.DecodeString(v.r)
+		wantR, _ := hex.DecodeString(v.R)
+
+		gotr, gotR := nonce(NewKeyFromSeed(seed), msg, ctx, v.preHash)
+		if !bytes.Equal(gotr, wantr) {
+			test.ReportError(t, gotr, wantr, v.name)
+		}
+		if !bytes.Equal(gotR, wantR) {
+			test.ReportError(t, gotR, wantR, v.name)
+		}
+	}
+}
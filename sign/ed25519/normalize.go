@@ -0,0 +1,50 @@
+package ed25519
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+)
+
+// ErrSignatureFormat is the error returned by NormalizeSignature for
+// encodings that are not recognized.
+var ErrSignatureFormat = errors.New("ed25519: unrecognized signature encoding")
+
+// NormalizeSignature returns the raw SignatureSize-byte form of the
+// signature encoded in b, which is either:
+//
+//   - the raw signature itself, returned unchanged;
+//   - the base64 encoding of the raw signature, padded or not, possibly
+//     surrounded by whitespace;
+//   - a PEM block, of any type, whose content is the raw signature.
+//
+// It returns ErrSignatureFormat for any other encoding, such as the ASN.1
+// DER wrappings used for ECDSA, which are not defined for Ed25519. It only
+// checks the encoding, so the signature must still be verified.
+func NormalizeSignature(b []byte) ([]byte, error) {
+	if len(b) == SignatureSize {
+		return b, nil
+	}
+
+	if block, rest := pem.Decode(b); block != nil {
+		if len(bytes.TrimSpace(rest)) != 0 || len(block.Bytes) != SignatureSize {
+			return nil, ErrSignatureFormat
+		}
+		return block.Bytes, nil
+	}
+
+	s := bytes.TrimSpace(b)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding} {
+		if enc.DecodedLen(len(s)) < SignatureSize {
+			continue
+		}
+		sig := make([]byte, enc.DecodedLen(len(s)))
+		n, err := enc.Decode(sig, s)
+		if err == nil && n == SignatureSize {
+			return sig[:n], nil
+		}
+	}
+
+	return nil, ErrSignatureFormat
+}
@@ -0,0 +1,61 @@
+package ed25519_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/sign/ed25519"
+)
+
+func TestNormalizeSignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := []byte("message")
+	sig := ed25519.Sign(priv, msg)
+
+	b64 := base64.StdEncoding.EncodeToString(sig)
+	for _, v := range []struct {
+		name string
+		in   []byte
+	}{
+		{"raw", sig},
+		{"base64", []byte(b64)},
+		{"base64 with whitespace", []byte("  " + b64 + "\n")},
+		{"unpadded base64", []byte(base64.RawStdEncoding.EncodeToString(sig))},
+		{"PEM", pem.EncodeToMemory(&pem.Block{Type: "SIGNATURE", Bytes: sig})},
+	} {
+		got, err := ed25519.NormalizeSignature(v.in)
+		test.CheckNoErr(t, err, v.name)
+		if !bytes.Equal(got, sig) {
+			test.ReportError(t, got, sig, v.name)
+		}
+		if !ed25519.Verify(pub, msg, got) {
+			t.Fatalf("%v: normalized signature must verify", v.name)
+		}
+	}
+
+	// SEQUENCE { OCTET STRING sig }
+	asn1 := append([]byte{0x30, 2 + ed25519.SignatureSize, 0x04, ed25519.SignatureSize}, sig...)
+	longSig := append(append([]byte{}, sig...), 0)
+	for _, v := range []struct {
+		name string
+		in   []byte
+	}{
+		{"ASN.1", asn1},
+		{"base64 of ASN.1", []byte(base64.StdEncoding.EncodeToString(asn1))},
+		{"PEM of ASN.1", pem.EncodeToMemory(&pem.Block{Type: "SIGNATURE", Bytes: asn1})},
+		{"PEM with trailing data", append(pem.EncodeToMemory(&pem.Block{Type: "SIGNATURE", Bytes: sig}), 'x')},
+		{"short", sig[:ed25519.SignatureSize-1]},
+		{"long", longSig},
+		{"invalid base64", []byte(b64[:len(b64)-4] + "!!==")},
+		{"empty", nil},
+	} {
+		_, err := ed25519.NormalizeSignature(v.in)
+		if err != ed25519.ErrSignatureFormat {
+			test.ReportError(t, err, ed25519.ErrSignatureFormat, v.name)
+		}
+	}
+}
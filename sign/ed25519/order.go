@@ -0,0 +1,30 @@
+package ed25519
+
+// HasFullOrder returns true if pub is the canonical encoding of a point A
+// of order L, the order of the base point. That is, A is not the identity
+// and [L]A is the identity, so A has no component in the subgroup of
+// order 8. Contributory protocols may require such keys, which Verify does
+// not check.
+//
+// It costs a variable-base scalar multiplication, which is about as much as
+// a signature verification.
+func (pub PublicKey) HasFullOrder() bool {
+	if len(pub) != PublicKeySize {
+		return false
+	}
+	var A pointR1
+	if ok := A.FromBytes(pub); !ok {
+		return false
+	}
+
+	var id pointR1
+	id.SetIdentity()
+	if A.isEqual(&id) {
+		return false
+	}
+
+	var zero [paramB]byte
+	var LA pointR1
+	LA.doubleMult(&A, zero[:], order[:])
+	return LA.isEqual(&id)
+}
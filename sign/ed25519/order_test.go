@@ -0,0 +1,44 @@
+package ed25519
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+)
+
+func TestHasFullOrder(t *testing.T) {
+	// A point of order 8, and the identity.
+	torsion, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	identity, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	var T pointR1
+	if !T.FromBytes(torsion) {
+		t.Fatal("invalid torsion point")
+	}
+	var T2 pointR2
+	T2.fromR1(&T)
+
+	for i := 0; i < 16; i++ {
+		pub, _, _ := GenerateKey(rand.Reader)
+		if !pub.HasFullOrder() {
+			test.ReportError(t, false, true, pub)
+		}
+
+		// A+T has order 8L.
+		var A pointR1
+		_ = A.FromBytes(pub)
+		A.add(&T2)
+		mixed := make(PublicKey, PublicKeySize)
+		_ = A.ToBytes(mixed)
+		if mixed.HasFullOrder() {
+			test.ReportError(t, true, false, mixed)
+		}
+	}
+
+	for _, pub := range []PublicKey{torsion, identity, torsion[:31], nil} {
+		if pub.HasFullOrder() {
+			test.ReportError(t, true, false, pub)
+		}
+	}
+}
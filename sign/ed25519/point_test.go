@@ -61,6 +61,40 @@ func TestPoint(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("double", func(t *testing.T) {
+		var P, Q, R pointR1
+		var S pointR2
+		k := make([]byte, paramB)
+		l := make([]byte, paramB)
+		for i := 0; i < testTimes; i++ {
+			randomPoint(&P)
+			_, _ = rand.Read(k[:])
+			_, _ = rand.Read(l[:])
+			l[paramB-1] &= 0x0F
+
+			// Q = kG + lP, computing lP with double-and-add.
+			S.fromR1(&P)
+			Q.SetIdentity()
+			for j := 8*paramB - 1; j >= 0; j-- {
+				Q.double()
+				if (l[j/8]>>uint(j%8))&1 == 1 {
+					Q.add(&S)
+				}
+			}
+			R.fixedMult(k[:])
+			S.fromR1(&R)
+			Q.add(&S)
+
+			R.doubleMult(&P, k[:], l[:])
+
+			got := Q.isEqual(&R)
+			want := true
+			if got != want {
+				test.ReportError(t, got, want, P, k, l)
+			}
+		}
+	})
 }
 
 var runLongBench = flag.Bool("long", false, "runs longer benchmark")
@@ -5,4 +5,7 @@ package ed25519
 import cryptoEd25519 "crypto/ed25519"
 
 // PublicKey is the type of Ed25519 public keys.
+//
+// As a PublicKey is a byte slice, slicing or assigning it aliases the key.
+// Use Bytes to obtain an independent copy.
 type PublicKey cryptoEd25519.PublicKey
@@ -0,0 +1,50 @@
+package ed25519
+
+import (
+	cryptoRand "crypto/rand"
+	"crypto/sha512"
+	"io"
+	"strconv"
+)
+
+// randomizedNonceSize is the number of random bytes mixed into the nonce by
+// SignRandomized.
+const randomizedNonceSize = 64
+
+// SignRandomized signs the message with priv as Sign does, except that the
+// nonce is derived from SHA-512(prefix || Z || M), where Z are 64 bytes read
+// from rand, rather than from SHA-512(prefix || M). If rand is nil,
+// crypto/rand.Reader will be used. It will panic if len(priv) is not
+// PrivateKeySize.
+//
+// This is not RFC 8032: signatures are not deterministic, though they are
+// verified by Verify as usual. The fresh randomness, as in XEdDSA, makes
+// the nonce differ when the same message is signed again, which hinders
+// fault attacks, where a faulty signature sharing its nonce with a correct
+// one leaks the private key, and side-channel attacks that need repeated
+// computations on the same nonce. As the secret prefix is still hashed in,
+// the nonce stays secret and uniform even if rand is broken.
+func (priv PrivateKey) SignRandomized(rand io.Reader, message []byte) ([]byte, error) {
+	if l := len(priv); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+
+	var Z [randomizedNonceSize]byte
+	if _, err := io.ReadFull(rand, Z[:]); err != nil {
+		return nil, err
+	}
+
+	H := sha512.New()
+	_, _ = H.Write(priv[:SeedSize])
+	h := H.Sum(nil)
+	clamp(h[:])
+	prefix, s := h[paramB:], h[:paramB]
+	prefix = append(prefix[:paramB:paramB], Z[:]...)
+
+	signature := make([]byte, SignatureSize)
+	signExpanded(signature, H, prefix, s, priv[SeedSize:], message, []byte(""), false)
+	return signature, nil
+}
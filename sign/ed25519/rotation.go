@@ -0,0 +1,37 @@
+package ed25519
+
+import "crypto/subtle"
+
+// VerifyAnyKey returns the index of the first public key of pubs for which
+// the signature of the message is valid as checked by Verify, and true; or
+// -1 and false if there is none. This is useful during key rotation, when a
+// message may be signed with any of several keys.
+//
+// It returns as soon as a key matches, so its running time reveals which
+// key matched. Use VerifyAnyKeyConstantTime if that must be hidden.
+func VerifyAnyKey(pubs []PublicKey, message, signature []byte) (int, bool) {
+	for i := range pubs {
+		if Verify(pubs[i], message, signature) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// VerifyAnyKeyConstantTime is like VerifyAnyKey, but always checks the
+// signature against all the keys, so that its running time does not depend
+// on which key matched. It still depends on the number of keys, and on
+// whether each of them can be decoded.
+func VerifyAnyKeyConstantTime(pubs []PublicKey, message, signature []byte) (int, bool) {
+	index, found := -1, 0
+	for i := range pubs {
+		ok := 0
+		if Verify(pubs[i], message, signature) {
+			ok = 1
+		}
+		first := ok & (found ^ 1)
+		index = subtle.ConstantTimeSelect(first, i, index)
+		found |= ok
+	}
+	return index, found == 1
+}
@@ -0,0 +1,31 @@
+package ed25519
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// Test 1 of RFC-8032, which is checked by the self-test.
+const (
+	selfTestSeed      = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	selfTestSignature = "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+)
+
+var errSelfTest = errors.New("ed25519: self-test failed")
+
+// selfTest signs the empty message with the key derived from selfTestSeed,
+// and checks that the signature is the hex-encoded want, and that it
+// verifies.
+func selfTest(want string) error {
+	seed, _ := hex.DecodeString(selfTestSeed)
+	priv := NewKeyFromSeed(seed)
+	sig := Sign(priv, nil)
+	if hex.EncodeToString(sig) != want {
+		return errSelfTest
+	}
+	if !Verify(priv.Public().(PublicKey), nil, sig) {
+		return errSelfTest
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// +build selftest
+
+package ed25519
+
+// Runs a known-answer test before Ed25519 is used when building with the
+// selftest tag, as required by power-on self-test policies.
+func init() {
+	if err := selfTest(selfTestSignature); err != nil {
+		panic(err)
+	}
+}
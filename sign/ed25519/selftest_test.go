@@ -0,0 +1,15 @@
+package ed25519
+
+import "testing"
+
+func TestSelfTest(t *testing.T) {
+	if err := selfTest(selfTestSignature); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := []byte(selfTestSignature)
+	corrupted[0] ^= 1
+	if err := selfTest(string(corrupted)); err == nil {
+		t.Fatal("self-test passes with a corrupted expected value")
+	}
+}
@@ -0,0 +1,68 @@
+package ed25519
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// ChainCodeSize is the size, in bytes, of the chain codes of SLIP-0010.
+	ChainCodeSize = 32
+	// HardenedIndex is the first index of hardened children in SLIP-0010.
+	HardenedIndex uint32 = 1 << 31
+)
+
+// ErrNotHardened is the error returned by DeriveChild for indices of
+// non-hardened children, which SLIP-0010 does not define for Ed25519.
+var ErrNotHardened = errors.New("ed25519: only hardened derivation is supported")
+
+// NewMasterKey returns the master private key and chain code derived from
+// seed as in SLIP-0010 for Ed25519, namely the halves of
+//
+//   HMAC-SHA512("ed25519 seed", seed).
+//
+// The seed is the root secret of a wallet, such as a BIP-39 seed, and
+// should have between 16 and 64 bytes.
+func NewMasterKey(seed []byte) (PrivateKey, [ChainCodeSize]byte) {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	_, _ = mac.Write(seed)
+	return splitDerivation(mac.Sum(nil))
+}
+
+// DeriveChild returns the private key and chain code of the child of priv
+// with the given index, as in SLIP-0010 for Ed25519, where chainCode is
+// the chain code of priv. The child is derived from the seed of priv as
+//
+//   HMAC-SHA512(chainCode, 0x00 ‖ seed ‖ index),
+//
+// with index in big-endian order. Only hardened children are defined for
+// Ed25519, so index must be at least HardenedIndex, otherwise
+// ErrNotHardened is returned. For instance, the child m/0' of the master
+// key m has index HardenedIndex + 0.
+func (priv PrivateKey) DeriveChild(chainCode [ChainCodeSize]byte, index uint32) (PrivateKey, [ChainCodeSize]byte, error) {
+	if index < HardenedIndex {
+		return nil, [ChainCodeSize]byte{}, ErrNotHardened
+	}
+
+	var data [1 + SeedSize + 4]byte
+	copy(data[1:], priv[:SeedSize])
+	binary.BigEndian.PutUint32(data[1+SeedSize:], index)
+
+	mac := hmac.New(sha512.New, chainCode[:])
+	_, _ = mac.Write(data[:])
+	child, c := splitDerivation(mac.Sum(nil))
+	for i := range data {
+		data[i] = 0
+	}
+	return child, c, nil
+}
+
+// splitDerivation returns the private key whose seed is the first half of
+// the output i of HMAC-SHA512, and the chain code in its second half.
+func splitDerivation(i []byte) (priv PrivateKey, chainCode [ChainCodeSize]byte) {
+	priv = NewKeyFromSeed(i[:SeedSize])
+	copy(chainCode[:], i[SeedSize:])
+	return
+}
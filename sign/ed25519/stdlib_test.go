@@ -0,0 +1,82 @@
+// +build go1.13
+
+package ed25519_test
+
+import (
+	"bytes"
+	cryptoEd25519 "crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"math/bits"
+	"testing"
+
+	"github.com/cloudflare/circl/internal/test"
+	"github.com/cloudflare/circl/sign/ed25519"
+)
+
+// Checks that keys and signatures match bit for bit those of crypto/ed25519,
+// and that each side accepts the signatures of the other.
+func TestStdlibInterop(t *testing.T) {
+	const testTimes = 1 << 8
+	seed := make([]byte, ed25519.SeedSize)
+	for i := 0; i < testTimes; i++ {
+		_, _ = rand.Read(seed)
+		msg := make([]byte, i)
+		_, _ = rand.Read(msg)
+
+		priv := ed25519.NewKeyFromSeed(seed)
+		pub := priv.Public().(ed25519.PublicKey)
+		stdPriv := cryptoEd25519.NewKeyFromSeed(seed)
+		stdPub := stdPriv.Public().(cryptoEd25519.PublicKey)
+
+		if !bytes.Equal(priv, stdPriv) {
+			test.ReportError(t, priv, stdPriv, seed)
+		}
+		if !bytes.Equal(pub, stdPub) {
+			test.ReportError(t, pub, stdPub, seed)
+		}
+
+		sig := ed25519.Sign(priv, msg)
+		stdSig := cryptoEd25519.Sign(stdPriv, msg)
+		if !bytes.Equal(sig, stdSig) {
+			test.ReportError(t, sig, stdSig, seed, msg)
+		}
+		if !cryptoEd25519.Verify(stdPub, msg, sig) {
+			test.ReportError(t, false, true, seed, msg)
+		}
+		if !ed25519.Verify(pub, msg, stdSig) {
+			test.ReportError(t, false, true, seed, msg)
+		}
+
+		// Both reject the malleated signature (R, S + order).
+		mal := addOrder(stdSig)
+		if got, want := ed25519.Verify(pub, msg, mal), cryptoEd25519.Verify(stdPub, msg, mal); got || want {
+			test.ReportError(t, got, want, seed, msg)
+		}
+
+		// And both reject a tampered message.
+		if len(msg) > 0 {
+			msg[0] ^= 1
+			if got, want := ed25519.Verify(pub, msg, sig), cryptoEd25519.Verify(stdPub, msg, sig); got || want {
+				test.ReportError(t, got, want, seed, msg)
+			}
+		}
+	}
+}
+
+// addOrder returns a copy of sig with the order of the group added to S.
+func addOrder(sig []byte) []byte {
+	order := [4]uint64{
+		0x5812631a5cf5d3ed, 0x14def9dea2f79cd6,
+		0x0000000000000000, 0x1000000000000000,
+	}
+	out := append([]byte{}, sig...)
+	S := out[ed25519.SignatureSize/2:]
+	var c uint64
+	for i := range order {
+		var s uint64
+		s, c = bits.Add64(binary.LittleEndian.Uint64(S[8*i:]), order[i], c)
+		binary.LittleEndian.PutUint64(S[8*i:], s)
+	}
+	return out
+}
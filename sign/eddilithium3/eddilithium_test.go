@@ -107,3 +107,16 @@ func TestPublicFromPrivate(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyMalformed(t *testing.T) {
+	var seed [32]byte
+	var msg [8]byte
+	var sig [eddilithium3.SignatureSize + 1]byte
+	pk, sk := eddilithium3.NewKeyFromSeed(&seed)
+	eddilithium3.SignTo(sk, msg[:], sig[:])
+	for _, l := range []int{0, 1, eddilithium3.SignatureSize - 1, eddilithium3.SignatureSize + 1} {
+		if eddilithium3.Verify(pk, msg[:], sig[:l]) {
+			t.Fatalf("signature of length %d accepted", l)
+		}
+	}
+}
@@ -87,7 +87,11 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 }
 
 // Verify checks whether the given signature by pk on msg is valid.
+// It returns false if signature is not of length SignatureSize.
 func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
+	if len(signature) != SignatureSize {
+		return false
+	}
 	if !mode4.Verify(
 		&pk.d,
 		msg,
@@ -109,3 +109,16 @@ func TestPublicFromPrivate(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyMalformed(t *testing.T) {
+	var seed [eddilithium4.SeedSize]byte
+	var msg [8]byte
+	var sig [eddilithium4.SignatureSize + 1]byte
+	pk, sk := eddilithium4.NewKeyFromSeed(&seed)
+	eddilithium4.SignTo(sk, msg[:], sig[:])
+	for _, l := range []int{0, 1, eddilithium4.SignatureSize - 1, eddilithium4.SignatureSize + 1} {
+		if eddilithium4.Verify(pk, msg[:], sig[:l]) {
+			t.Fatalf("signature of length %d accepted", l)
+		}
+	}
+}